@@ -1,38 +1,75 @@
 package errors
 
-const CodeServerInternalError = 500000     // 服务内部错误
-const CodeRequestParamError = 400000       // 请求参数错误
-const CodeRequestPathError = 400001        // 请求path错误
-const CodeRequestTokenInvalid = 400002     // 请求token无效
-const CodeRequestTokenExpired = 400003     // 请求token过期
-const CodeRequestJSONDecodeFailed = 400004 // 请求的 JSON 解释失败
-
-var ServerInternalError = Error{
-	ErrorCode:    CodeServerInternalError,
-	ErrorMessage: "服务内部错误",
-}
-
-var RequestParamError = Error{
-	ErrorCode:    CodeRequestParamError,
-	ErrorMessage: "请求参数错误",
-}
-
-var RequestPathError = Error{
-	ErrorCode:    CodeRequestPathError,
-	ErrorMessage: "请求path错误",
-}
-
-var RequestTokenInvalid = Error{
-	ErrorCode:    CodeRequestTokenInvalid,
-	ErrorMessage: "请求token无效",
-}
-
-var RequestTokenExpired = Error{
-	ErrorCode:    CodeRequestTokenExpired,
-	ErrorMessage: "请求token过期",
-}
-
-var RequestJSONDecodeFailed = Error{
-	ErrorCode:    CodeRequestJSONDecodeFailed,
-	ErrorMessage: "请求的 JSON 解释失败",
-}
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+const CodeServerInternalError = 500000      // 服务内部错误
+const CodeServiceUnavailable = 503000       // 服务不可用
+const CodeRequestParamError = 400000        // 请求参数错误
+const CodeRequestPathError = 400001         // 请求path错误
+const CodeRequestTokenInvalid = 400002      // 请求token无效
+const CodeRequestTokenExpired = 400003      // 请求token过期
+const CodeRequestJSONDecodeFailed = 400004  // 请求的 JSON 解释失败
+const CodeRequestTokenRevoked = 400005      // 请求token已注销
+const CodeRequestSignatureInvalid = 400006  // 请求签名无效
+const CodeRequestSignatureExpired = 400007  // 请求签名过期
+const CodeRequestNonceReused = 400008       // 请求nonce重复
+const CodeLoggedInElsewhere = 400009        // 账号已在其他设备登录
+const CodeForbidden = 403000                // 没有权限
+const CodeIdempotencyKeyProcessing = 409000 // 相同幂等键的请求正在处理
+const CodeRequestEntityTooLarge = 413000    // 请求体过大
+const CodeIdempotencyKeyReused = 422000     // 幂等键被用于不同的请求
+
+// BaseCodeRange is reserved by base library, the services should use other ranges
+var BaseCodeRange = NewCodeRange("base", 0, 99)
+
+var ServerInternalError = BaseCodeRange.Register(CodeServerInternalError, http.StatusInternalServerError, codes.Internal,
+	"Internal Server Error")
+
+var ServiceUnavailable = BaseCodeRange.Register(CodeServiceUnavailable, http.StatusServiceUnavailable, codes.Unavailable,
+	"Service Unavailable")
+
+var RequestParamError = BaseCodeRange.Register(CodeRequestParamError, http.StatusBadRequest, codes.InvalidArgument,
+	"request param error")
+
+var RequestPathError = BaseCodeRange.Register(CodeRequestPathError, http.StatusNotFound, codes.NotFound,
+	"request path error")
+
+var RequestTokenInvalid = BaseCodeRange.Register(CodeRequestTokenInvalid, http.StatusUnauthorized, codes.Unauthenticated,
+	"request 'token' is invalid")
+
+var RequestTokenExpired = BaseCodeRange.Register(CodeRequestTokenExpired, http.StatusUnauthorized, codes.Unauthenticated,
+	"request 'token' is expired")
+
+var RequestJSONDecodeFailed = BaseCodeRange.Register(CodeRequestJSONDecodeFailed, http.StatusBadRequest, codes.InvalidArgument,
+	"request json decode failed")
+
+var RequestTokenRevoked = BaseCodeRange.Register(CodeRequestTokenRevoked, http.StatusUnauthorized, codes.Unauthenticated,
+	"request 'token' is revoked")
+
+var RequestSignatureInvalid = BaseCodeRange.Register(CodeRequestSignatureInvalid, http.StatusUnauthorized, codes.Unauthenticated,
+	"request signature is invalid")
+
+var RequestSignatureExpired = BaseCodeRange.Register(CodeRequestSignatureExpired, http.StatusUnauthorized, codes.Unauthenticated,
+	"request signature is expired")
+
+var RequestNonceReused = BaseCodeRange.Register(CodeRequestNonceReused, http.StatusUnauthorized, codes.Unauthenticated,
+	"request nonce is reused")
+
+var LoggedInElsewhere = BaseCodeRange.Register(CodeLoggedInElsewhere, http.StatusUnauthorized, codes.Unauthenticated,
+	"logged in elsewhere")
+
+var Forbidden = BaseCodeRange.Register(CodeForbidden, http.StatusForbidden, codes.PermissionDenied,
+	"permission denied")
+
+var IdempotencyKeyProcessing = BaseCodeRange.Register(CodeIdempotencyKeyProcessing, http.StatusConflict, codes.Aborted,
+	"request with the same idempotency key is processing")
+
+var RequestEntityTooLarge = BaseCodeRange.Register(CodeRequestEntityTooLarge, http.StatusRequestEntityTooLarge, codes.ResourceExhausted,
+	"request entity too large")
+
+var IdempotencyKeyReused = BaseCodeRange.Register(CodeIdempotencyKeyReused, http.StatusUnprocessableEntity, codes.FailedPrecondition,
+	"idempotency key is reused with a different request")
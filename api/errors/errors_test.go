@@ -0,0 +1,98 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestError_Is(t *testing.T) {
+
+	cause := stderrors.New("record not found")
+	wrapped := fmt.Errorf("query: %w", RequestPathError.Wrap(cause))
+
+	assert.True(t, stderrors.Is(wrapped, RequestPathError))
+	assert.True(t, stderrors.Is(wrapped, &RequestPathError))
+	assert.True(t, stderrors.Is(wrapped, cause))
+	assert.False(t, stderrors.Is(wrapped, RequestParamError))
+	assert.True(t, stderrors.Is(RequestParamError.WithMessage("name is required"), RequestParamError))
+
+	assert.Equal(t, "query: request path error: record not found", wrapped.Error())
+}
+
+func TestFromError(t *testing.T) {
+
+	tests := []struct {
+		input    error
+		wantOk   bool
+		wantCode int
+	}{
+		{input: ServerInternalError, wantOk: true, wantCode: CodeServerInternalError},
+		{input: &RequestParamError, wantOk: true, wantCode: CodeRequestParamError},
+		{input: fmt.Errorf("wrapped: %w", &RequestTokenInvalid), wantOk: true, wantCode: CodeRequestTokenInvalid},
+		{input: stderrors.New("plain"), wantOk: false},
+		{input: nil, wantOk: false},
+	}
+
+	for _, test := range tests {
+
+		err, ok := FromError(test.input)
+		assert.Equal(t, test.wantOk, ok, test.input)
+		assert.Equal(t, test.wantCode, err.Code(), test.input)
+	}
+}
+
+func TestError_GetHTTPStatus(t *testing.T) {
+
+	assert.Equal(t, http.StatusNotFound, RequestPathError.GetHTTPStatus())
+	assert.Equal(t, http.StatusConflict, Error{ErrorCode: 409001}.GetHTTPStatus())
+	assert.Equal(t, http.StatusUnauthorized, Error{ErrorCode: CodeRequestTokenExpired}.GetHTTPStatus())
+	assert.Equal(t, http.StatusInternalServerError, Error{ErrorCode: 1}.GetHTTPStatus())
+
+	assert.Equal(t, codes.Unauthenticated, Error{ErrorCode: CodeRequestTokenExpired}.GetGRPCCode())
+	assert.Equal(t, codes.Unknown, Error{ErrorCode: 409001}.GetGRPCCode())
+}
+
+func TestRegister(t *testing.T) {
+
+	err := Register(418900, http.StatusTeapot, codes.Unimplemented, "I'm a teapot")
+	registered, ok := Lookup(418900)
+	assert.True(t, ok)
+	assert.Equal(t, err, registered)
+
+	assert.Panics(t, func() {
+		Register(418900, http.StatusTeapot, codes.Unimplemented, "duplicate")
+	})
+	assert.Panics(t, func() {
+		Register(CodeServerInternalError, http.StatusInternalServerError, codes.Internal, "duplicate")
+	})
+}
+
+func TestCodeRange(t *testing.T) {
+
+	user := NewCodeRange("user", 100, 199)
+
+	assert.True(t, user.Contains(404100))
+	assert.True(t, user.Contains(400199))
+	assert.False(t, user.Contains(400200))
+
+	notFound := user.Register(404100, http.StatusNotFound, codes.NotFound, "user not found")
+	assert.Equal(t, 404100, notFound.Code())
+
+	assert.Panics(t, func() {
+		user.Register(404200, http.StatusNotFound, codes.NotFound, "out of range")
+	})
+	assert.Panics(t, func() {
+		NewCodeRange("order", 150, 249)
+	})
+	assert.Panics(t, func() {
+		NewCodeRange("base2", 50, 60)
+	})
+	assert.Panics(t, func() {
+		NewCodeRange("invalid", 900, 1000)
+	})
+}
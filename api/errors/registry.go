@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	registry     = make(map[uint32]Error)
+	ranges       = make([]*CodeRange, 0)
+	registryLock sync.RWMutex
+)
+
+// Register registers the code with http status, grpc code and default message.
+// It panics when the code is registered already, so the duplicate codes are found at init,
+// usage: var UserNotFound = errors.Register(404100, http.StatusNotFound, codes.NotFound, "user not found")
+func Register(code uint32, httpStatus int, grpcCode codes.Code, message string) Error {
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if registered, exist := registry[code]; exist {
+		panic(fmt.Sprintf("error code %d is registered already with message: %s", code, registered.ErrorMessage))
+	}
+
+	err := Error{
+		ErrorCode:    code,
+		ErrorMessage: message,
+		httpStatus:   httpStatus,
+		grpcCode:     grpcCode,
+	}
+
+	registry[code] = err
+
+	return err
+}
+
+// Lookup returns the registered error of code
+func Lookup(code uint32) (Error, bool) {
+
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	err, exist := registry[code]
+	return err, exist
+}
+
+// Registered returns all registered errors ordered by code, e.g. used to generate error code document
+func Registered() []Error {
+
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	errs := make([]Error, 0, len(registry))
+	for _, err := range registry {
+		errs = append(errs, err)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].ErrorCode < errs[j].ErrorCode
+	})
+
+	return errs
+}
+
+// CodeRange is the range of sequence (code % 1000) owned by a service,
+// so the services can define codes of any http status without conflicts,
+// e.g. the range 100-199 owns 400100-400199, 404100-404199 and so on.
+type CodeRange struct {
+	Name string
+	Min  uint32
+	Max  uint32
+}
+
+// NewCodeRange reserves the sequence range for service, it panics when overlaps with others.
+func NewCodeRange(name string, min, max uint32) *CodeRange {
+
+	if min > max || max > 999 {
+		panic(fmt.Sprintf("error code range %s [%d, %d] is invalid", name, min, max))
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	for _, reserved := range ranges {
+		if min <= reserved.Max && reserved.Min <= max {
+			panic(fmt.Sprintf("error code range %s [%d, %d] overlaps with %s [%d, %d]",
+				name, min, max, reserved.Name, reserved.Min, reserved.Max))
+		}
+	}
+
+	codeRange := &CodeRange{Name: name, Min: min, Max: max}
+	ranges = append(ranges, codeRange)
+
+	return codeRange
+}
+
+func (codeRange *CodeRange) Contains(code uint32) bool {
+
+	sequence := code % 1000
+	return sequence >= codeRange.Min && sequence <= codeRange.Max
+}
+
+// Register registers the code in range, it panics when the code is out of range or registered already.
+func (codeRange *CodeRange) Register(code uint32, httpStatus int, grpcCode codes.Code, message string) Error {
+
+	if !codeRange.Contains(code) {
+		panic(fmt.Sprintf("error code %d is out of range %s [%d, %d]", code, codeRange.Name, codeRange.Min, codeRange.Max))
+	}
+
+	return Register(code, httpStatus, grpcCode, message)
+}
@@ -1,6 +1,120 @@
 package errors
 
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Error is the business error, created by Register with http status, grpc code and default message.
+// It's comparable by code with errors.Is, so the errors with different message or cause are the same error.
 type Error struct {
 	ErrorCode    uint32 `json:"errorCode"`
 	ErrorMessage string `json:"errorMessage"`
+
+	httpStatus int
+	grpcCode   codes.Code
+	cause      error
+}
+
+func (err Error) Error() string {
+
+	if err.cause != nil {
+		return fmt.Sprintf("%s: %s", err.ErrorMessage, err.cause)
+	}
+
+	return err.ErrorMessage
+}
+
+func (err Error) Code() int {
+	return int(err.ErrorCode)
+}
+
+func (err Error) Message() string {
+	return err.ErrorMessage
+}
+
+// GetHTTPStatus returns the registered http status, the status is inferred from code if not registered,
+// because the code is http status * 1000 + sequence.
+func (err Error) GetHTTPStatus() int {
+
+	if err.httpStatus != 0 {
+		return err.httpStatus
+	}
+
+	if registered, ok := Lookup(err.ErrorCode); ok && registered.httpStatus != 0 {
+		return registered.httpStatus
+	}
+
+	if status := int(err.ErrorCode / 1000); http.StatusText(status) != "" {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (err Error) GetGRPCCode() codes.Code {
+
+	if err.grpcCode != codes.OK {
+		return err.grpcCode
+	}
+
+	if registered, ok := Lookup(err.ErrorCode); ok && registered.grpcCode != codes.OK {
+		return registered.grpcCode
+	}
+
+	return codes.Unknown
+}
+
+func (err Error) Unwrap() error {
+	return err.cause
+}
+
+// Is reports whether target is an Error with the same code
+func (err Error) Is(target error) bool {
+
+	switch target := target.(type) {
+	case Error:
+		return target.ErrorCode == err.ErrorCode
+	case *Error:
+		return target != nil && target.ErrorCode == err.ErrorCode
+	}
+
+	return false
+}
+
+// Wrap returns a copy of error with cause, the cause can be got by errors.Unwrap,
+// usage: return errors.ServerInternalError.Wrap(err)
+func (err Error) Wrap(cause error) Error {
+	err.cause = cause
+	return err
+}
+
+// WithMessage returns a copy of error with message instead of the default message
+func (err Error) WithMessage(message string) Error {
+	err.ErrorMessage = message
+	return err
+}
+
+func (err Error) WithMessagef(format string, args ...interface{}) Error {
+	err.ErrorMessage = fmt.Sprintf(format, args...)
+	return err
+}
+
+// FromError finds the first Error in the chain of err
+func FromError(err error) (Error, bool) {
+
+	var value Error
+	if stderrors.As(err, &value) {
+		return value, true
+	}
+
+	var pointer *Error
+	if stderrors.As(err, &pointer) && pointer != nil {
+		return *pointer, true
+	}
+
+	return Error{}, false
 }
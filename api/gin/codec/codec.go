@@ -0,0 +1,142 @@
+package codec
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang/protobuf/proto"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatXML      Format = "xml"
+	FormatMsgPack  Format = "msgpack"
+	FormatProtobuf Format = "protobuf"
+)
+
+const (
+	MIMEJSON      = binding.MIMEJSON
+	MIMEXML       = binding.MIMEXML
+	MIMEXML2      = binding.MIMEXML2
+	MIMEMsgPack   = binding.MIMEMSGPACK
+	MIMEMsgPack2  = binding.MIMEMSGPACK2
+	MIMEProtobuf  = binding.MIMEPROTOBUF
+	MIMEProtobuf2 = "application/protobuf"
+)
+
+var mimeFormats = map[string]Format{
+	MIMEJSON:      FormatJSON,
+	MIMEXML:       FormatXML,
+	MIMEXML2:      FormatXML,
+	MIMEMsgPack:   FormatMsgPack,
+	MIMEMsgPack2:  FormatMsgPack,
+	MIMEProtobuf:  FormatProtobuf,
+	MIMEProtobuf2: FormatProtobuf,
+}
+
+var formatMIMEs = map[Format][]string{
+	FormatJSON:     {MIMEJSON},
+	FormatXML:      {MIMEXML, MIMEXML2},
+	FormatMsgPack:  {MIMEMsgPack, MIMEMsgPack2},
+	FormatProtobuf: {MIMEProtobuf, MIMEProtobuf2},
+}
+
+var (
+	lock          sync.RWMutex
+	defaultFormat = FormatJSON
+	offered       = newOffered(FormatJSON)
+)
+
+// SetDefaultFormat sets the format used when request has no Accept header, or accepts any format.
+func SetDefaultFormat(format Format) error {
+
+	if _, ok := formatMIMEs[format]; !ok {
+		return fmt.Errorf("unsupported format %s", format)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	defaultFormat = format
+	offered = newOffered(format)
+
+	return nil
+}
+
+func GetDefaultFormat() Format {
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return defaultFormat
+}
+
+// newOffered puts the mime types of default format first, so */* matches the default format
+func newOffered(format Format) []string {
+
+	mimes := append([]string{}, formatMIMEs[format]...)
+	for _, other := range []Format{FormatJSON, FormatXML, FormatMsgPack, FormatProtobuf} {
+		if other != format {
+			mimes = append(mimes, formatMIMEs[other]...)
+		}
+	}
+
+	return mimes
+}
+
+// Negotiate returns the response format by Accept header, the default format is used if nothing matches.
+func Negotiate(c *gin.Context) Format {
+
+	lock.RLock()
+	mimes, format := offered, defaultFormat
+	lock.RUnlock()
+
+	if c == nil || c.Request == nil {
+		return format
+	}
+
+	if negotiated, ok := mimeFormats[c.NegotiateFormat(mimes...)]; ok {
+		return negotiated
+	}
+
+	return format
+}
+
+// Binding returns the binding of request Content-Type, JSON is used when Content-Type is not supported,
+// because clients often send json without Content-Type.
+func Binding(c *gin.Context) binding.Binding {
+
+	contentType := ""
+	if c != nil && c.Request != nil {
+		contentType = strings.ToLower(c.ContentType())
+	}
+
+	switch mimeFormats[contentType] {
+	case FormatXML:
+		return binding.XML
+	case FormatMsgPack:
+		return binding.MsgPack
+	case FormatProtobuf:
+		return binding.ProtoBuf
+	}
+
+	return binding.JSON
+}
+
+// Bind decodes request body by Content-Type, the protobuf body requires obj implements proto.Message.
+func Bind(c *gin.Context, obj interface{}) error {
+
+	b := Binding(c)
+	if b == binding.ProtoBuf {
+		if _, ok := obj.(proto.Message); !ok {
+			return fmt.Errorf("protobuf body is not supported by %T", obj)
+		}
+	}
+
+	return c.ShouldBindWith(obj, b)
+}
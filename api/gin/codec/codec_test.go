@@ -0,0 +1,96 @@
+package codec
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(header http.Header, body []byte) *gin.Context {
+
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	for key, values := range header {
+		ctx.Request.Header[key] = values
+	}
+
+	return ctx
+}
+
+func TestNegotiate(t *testing.T) {
+
+	tests := []struct {
+		defaultFormat Format
+		accept        string
+		want          Format
+	}{
+		{defaultFormat: FormatJSON, accept: "", want: FormatJSON},
+		{defaultFormat: FormatJSON, accept: "*/*", want: FormatJSON},
+		{defaultFormat: FormatXML, accept: "*/*", want: FormatXML},
+		{defaultFormat: FormatJSON, accept: "application/xml", want: FormatXML},
+		{defaultFormat: FormatJSON, accept: "text/xml;q=0.9", want: FormatXML},
+		{defaultFormat: FormatJSON, accept: "application/x-msgpack", want: FormatMsgPack},
+		{defaultFormat: FormatJSON, accept: "application/x-protobuf", want: FormatProtobuf},
+		{defaultFormat: FormatJSON, accept: "application/protobuf", want: FormatProtobuf},
+		{defaultFormat: FormatMsgPack, accept: "text/html", want: FormatMsgPack},
+	}
+
+	for _, test := range tests {
+
+		assert.NoError(t, SetDefaultFormat(test.defaultFormat))
+
+		ctx := newContext(http.Header{"Accept": {test.accept}}, nil)
+		assert.Equal(t, test.want, Negotiate(ctx), test.accept)
+	}
+
+	assert.NoError(t, SetDefaultFormat(FormatJSON))
+	assert.Error(t, SetDefaultFormat("yaml"))
+	assert.Equal(t, FormatJSON, GetDefaultFormat())
+	assert.Equal(t, FormatJSON, Negotiate(nil))
+}
+
+func TestBind(t *testing.T) {
+
+	type request struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+
+	protoBody, err := proto.Marshal(&wrappers.StringValue{Value: "protobuf"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{contentType: "", body: []byte(`{"name":"default"}`), want: "default"},
+		{contentType: "application/json", body: []byte(`{"name":"json"}`), want: "json"},
+		{contentType: "application/xml", body: []byte(`<request><name>xml</name></request>`), want: "xml"},
+		{contentType: "text/xml; charset=utf-8", body: []byte(`<request><name>text</name></request>`), want: "text"},
+		{contentType: "application/x-msgpack", body: []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa7, 'm', 's', 'g', 'p', 'a', 'c', 'k'}, want: "msgpack"},
+	}
+
+	for _, test := range tests {
+
+		ctx := newContext(http.Header{"Content-Type": {test.contentType}}, test.body)
+
+		value := &request{}
+		assert.NoError(t, Bind(ctx, value), test.contentType)
+		assert.Equal(t, test.want, value.Name, test.contentType)
+	}
+
+	ctx := newContext(http.Header{"Content-Type": {MIMEProtobuf}}, protoBody)
+	assert.Error(t, Bind(ctx, &request{}))
+
+	ctx = newContext(http.Header{"Content-Type": {MIMEProtobuf}}, protoBody)
+	message := &wrappers.StringValue{}
+	assert.NoError(t, Bind(ctx, message))
+	assert.Equal(t, "protobuf", message.Value)
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	log2 "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/util/log"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+// Authorize responds errors.Forbidden if the user doesn't meet the requirement, it's registered after authentication, e.g.
+// engine.Use(middleware.WithPath(auth.RequirePermissions(policy, "order:write"), "/orders"))
+func Authorize(policy *auth.Policy, requirement auth.Requirement) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		claims := GetUserClaims(c)
+		if claims == nil {
+			c.Abort()
+			response.TokenInvalid(c)
+			return
+		}
+
+		if !policy.Authorize(claims, requirement) {
+			log2.RequestEntry(c).
+				WithField("userId", claims.GetUserId()).
+				WithField("roles", claims.GetRoles()).
+				WithField("requirement", requirement).
+				Info("permission denied")
+			c.Abort()
+			response.Error(c, errors.Forbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRoles requires the user has any of roles.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return Authorize(nil, auth.Requirement{Roles: roles})
+}
+
+// RequirePermissions requires the roles of user are granted all of permissions by policy.
+func RequirePermissions(policy *auth.Policy, permissions ...string) gin.HandlerFunc {
+	return Authorize(policy, auth.Requirement{Permissions: permissions})
+}
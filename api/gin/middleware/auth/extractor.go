@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bearerScheme = "bearer "
+
+// TokenExtractor gets the token from request, it returns empty string if not found.
+type TokenExtractor func(c *gin.Context) string
+
+// DefaultTokenExtractors reads the Authorization header only, the query parameter is not read by default,
+// because the url is logged by proxies and access logs.
+var DefaultTokenExtractors = []TokenExtractor{FromAuthorizationHeader()}
+
+// FromAuthorizationHeader reads the Authorization header, the Bearer scheme is optional for compatibility.
+func FromAuthorizationHeader() TokenExtractor {
+	return func(c *gin.Context) string {
+		return trimBearer(c.GetHeader(RequestUserToken))
+	}
+}
+
+// FromHeader reads the custom header, e.g. X-Token, the header should be redacted by request.AccessLogRedactHeaders.
+func FromHeader(name string) TokenExtractor {
+	return func(c *gin.Context) string {
+		return strings.TrimSpace(c.GetHeader(name))
+	}
+}
+
+func FromCookie(name string) TokenExtractor {
+	return func(c *gin.Context) string {
+		value, err := c.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+}
+
+// FromQuery reads the query parameter, e.g. for websocket and EventSource which can't set headers,
+// the parameter should be redacted by request.AccessLogRedactFields if it's not in the defaults.
+func FromQuery(name string) TokenExtractor {
+	return func(c *gin.Context) string {
+		return strings.TrimSpace(c.Query(name))
+	}
+}
+
+// ExtractToken returns the first token found by extractors.
+func ExtractToken(c *gin.Context, extractors ...TokenExtractor) string {
+
+	for _, extractor := range extractors {
+		if token := extractor(c); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+func trimBearer(value string) string {
+
+	value = strings.TrimSpace(value)
+	if len(value) > len(bearerScheme) && strings.EqualFold(value[:len(bearerScheme)], bearerScheme) {
+		return strings.TrimSpace(value[len(bearerScheme):])
+	}
+
+	return value
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+func TestExtractToken(t *testing.T) {
+
+	extractors := []TokenExtractor{FromAuthorizationHeader(), FromHeader("X-Token"), FromCookie("token"), FromQuery("access_token")}
+
+	tests := []struct {
+		setup func(request *http.Request)
+		want  string
+	}{
+		{setup: func(request *http.Request) { request.Header.Set("Authorization", "Bearer abc") }, want: "abc"},
+		{setup: func(request *http.Request) { request.Header.Set("Authorization", "bearer  abc ") }, want: "abc"},
+		{setup: func(request *http.Request) { request.Header.Set("Authorization", "abc") }, want: "abc"},
+		{setup: func(request *http.Request) { request.Header.Set("X-Token", "def") }, want: "def"},
+		{setup: func(request *http.Request) { request.AddCookie(&http.Cookie{Name: "token", Value: "ghi"}) }, want: "ghi"},
+		{setup: func(request *http.Request) { request.URL.RawQuery = "access_token=jkl" }, want: "jkl"},
+		{setup: func(request *http.Request) {
+			request.Header.Set("X-Token", "def")
+			request.Header.Set("Authorization", "Bearer abc")
+		}, want: "abc"},
+		{setup: func(request *http.Request) {}, want: ""},
+	}
+
+	for index, test := range tests {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		test.setup(c.Request)
+
+		assert.Equal(t, test.want, ExtractToken(c, extractors...), index)
+	}
+}
+
+func TestUserJwtAuthentication_Optional(t *testing.T) {
+
+	token, _, err := auth.NewUserJwtToken(1, nil, "secret")
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(UserJwtAuthentication("secret", Optional(), TokenExtractors(FromAuthorizationHeader(), FromQuery("access_token"))))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "%d", GetUserId(c))
+	})
+
+	tests := []struct {
+		url           string
+		authorization string
+		status        int
+		body          string
+	}{
+		{url: "/", status: http.StatusOK, body: "0"},
+		{url: "/", authorization: "Bearer " + token, status: http.StatusOK, body: "1"},
+		{url: "/?access_token=" + token, status: http.StatusOK, body: "1"},
+		{url: "/", authorization: "Bearer invalid", status: http.StatusUnauthorized},
+	}
+
+	for index, test := range tests {
+
+		request := httptest.NewRequest(http.MethodGet, test.url, nil)
+		if test.authorization != "" {
+			request.Header.Set("Authorization", test.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, test.status, recorder.Code, index)
+		if test.body != "" {
+			assert.Equal(t, test.body, recorder.Body.String(), index)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
 	log2 "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/util/log"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
@@ -15,15 +20,88 @@ const UserInformation = "userInfo"
 // 用户token header名称
 const RequestUserToken = "Authorization"
 
+const Claims = "claims"
+const UserClaims = "userClaims"
+
+type Config struct {
+	RevocationChecker auth.RevocationChecker
+	SessionChecker    auth.SessionChecker
+	Extractors        []TokenExtractor
+	Optional          bool
+}
+
+type Option func(config *Config)
+
+// Revocation rejects the revoked tokens, e.g. auth.TokenManager.
+func Revocation(checker auth.RevocationChecker) Option {
+	return func(config *Config) {
+		config.RevocationChecker = checker
+	}
+}
+
+// Sessions rejects the tokens of superseded sessions with errors.LoggedInElsewhere, e.g. auth.SessionManager.
+func Sessions(checker auth.SessionChecker) Option {
+	return func(config *Config) {
+		config.SessionChecker = checker
+	}
+}
+
+// TokenExtractors replaces DefaultTokenExtractors, the first token found is used, e.g.
+//
+//	auth.TokenExtractors(auth.FromAuthorizationHeader(), auth.FromCookie("token"), auth.FromQuery("access_token"))
+func TokenExtractors(extractors ...TokenExtractor) Option {
+	return func(config *Config) {
+		config.Extractors = extractors
+	}
+}
+
+// Optional lets the anonymous requests pass, the user id is 0 for them,
+// the requests with invalid or expired token are still rejected, so the client can refresh token.
+func Optional() Option {
+	return func(config *Config) {
+		config.Optional = true
+	}
+}
+
 /* gin用户jwt认证中间件 */
-func UserJwtAuthentication(tokenKey string) gin.HandlerFunc {
+func UserJwtAuthentication(tokenKey string, options ...Option) gin.HandlerFunc {
+	return authenticate(func(tokenString string, log *logrus.Entry) (jwt.MapClaims, error) {
+		return auth.ParseJWTToken(tokenString, tokenKey, log)
+	}, options...)
+}
+
+// JWTAuthentication verifies tokens by the keys of j, e.g. RS256 keys with rotation.
+func JWTAuthentication(j *auth.JWT, options ...Option) gin.HandlerFunc {
+	return authenticate(j.Parse, options...)
+}
+
+// JWKSHandler responds the public keys of j, it's registered on /.well-known/jwks.json usually.
+func JWKSHandler(j *auth.JWT) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, j.JWKS())
+	}
+}
+
+type parser func(tokenString string, log *logrus.Entry) (jwt.MapClaims, error)
+
+func authenticate(parse parser, options ...Option) gin.HandlerFunc {
+
+	config := &Config{Extractors: DefaultTokenExtractors}
+	for _, option := range options {
+		option(config)
+	}
+
 	return func(c *gin.Context) {
-		tokenVal := c.GetHeader(RequestUserToken)
-		if len(tokenVal) == 0 {
-			tokenVal = c.Query(RequestUserToken)
+		tokenVal := ExtractToken(c, config.Extractors...)
+		if tokenVal == "" && config.Optional {
+			c.Next()
+			return
 		}
 
-		userId, info, err := auth.ResolveJWTToken(tokenVal, tokenKey, log2.RequestEntry(c))
+		log := log2.RequestEntry(c)
+
+		claims, err := parse(tokenVal, log)
 		if err != nil {
 			c.Abort()
 
@@ -36,8 +114,68 @@ func UserJwtAuthentication(tokenKey string) gin.HandlerFunc {
 			return
 		}
 
+		if config.RevocationChecker != nil {
+
+			revoked, err := config.RevocationChecker.IsRevoked(c.Request.Context(), claims)
+			if err != nil {
+				log.WithError(err).Error("check token revocation failed")
+				c.Abort()
+				response.Error(c, errors.ServiceUnavailable)
+				return
+			}
+
+			if revoked {
+				c.Abort()
+				response.TokenRevoked(c)
+				return
+			}
+		}
+
+		if config.SessionChecker != nil {
+
+			if err = config.SessionChecker.CheckSession(c.Request.Context(), claims); err != nil {
+				c.Abort()
+
+				switch {
+				case auth.IsSessionSuperseded(err):
+					response.Error(c, errors.LoggedInElsewhere)
+				case auth.IsTokenRevoked(err):
+					response.TokenRevoked(c)
+				case auth.IsTokenInvalid(err):
+					response.TokenInvalid(c)
+				default:
+					log.WithError(err).Error("check token session failed")
+					response.Error(c, errors.ServiceUnavailable)
+				}
+				return
+			}
+		}
+
+		userId, info, err := auth.ResolveClaims(claims, log)
+		if err != nil {
+			c.Abort()
+			response.TokenInvalid(c)
+			return
+		}
+
+		userClaims, err := auth.DecodeClaims(claims)
+		if err != nil {
+			log.WithError(err).Warn("decode user claims failed")
+			c.Abort()
+			response.TokenInvalid(c)
+			return
+		}
+
 		c.Set(UserID, userId)
 		c.Set(UserInformation, info)
+		c.Set(Claims, claims)
+		c.Set(UserClaims, userClaims)
+
+		// the context is used to call grpc services, the token is forwarded by interceptor.ForwardTokenCredentials
+		ctx := auth.NewContext(c.Request.Context(), userClaims)
+		ctx = auth.NewInfoContext(ctx, info)
+		ctx = auth.NewTokenContext(ctx, tokenVal)
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
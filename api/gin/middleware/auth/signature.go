@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/gin-gonic/gin"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	log2 "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/util/log"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+const AccessKeyID = "accessKeyId"
+
+// SignatureAuthentication verifies the HMAC signature of requests signed by auth.Signer,
+// it's for partner callbacks and internal calls, the access key id is set in context.
+func SignatureAuthentication(verifier *auth.SignatureVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		log := log2.RequestEntry(c)
+
+		var body []byte
+		if c.Request.Body != nil {
+
+			var err error
+			body, err = ioutil.ReadAll(c.Request.Body)
+			if err != nil {
+				log.WithError(err).Warn("read request body failed")
+				c.Abort()
+				response.Error(c, errors.RequestParamError)
+				return
+			}
+
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		accessKeyId, err := verifier.Verify(c.Request, body)
+		if err != nil {
+			log.WithError(err).WithField(AccessKeyID, accessKeyId).Warn("verify request signature failed")
+			c.Abort()
+
+			switch {
+			case auth.IsSignatureMissing(err), auth.IsSignatureInvalid(err):
+				response.Error(c, errors.RequestSignatureInvalid)
+			case auth.IsSignatureExpired(err):
+				response.Error(c, errors.RequestSignatureExpired)
+			case auth.IsNonceReused(err):
+				response.Error(c, errors.RequestNonceReused)
+			default:
+				response.Error(c, errors.ServiceUnavailable)
+			}
+			return
+		}
+
+		c.Set(AccessKeyID, accessKeyId)
+		c.Next()
+	}
+}
+
+// GetAccessKeyId returns the access key id verified by SignatureAuthentication.
+func GetAccessKeyId(c *gin.Context) string {
+	return c.GetString(AccessKeyID)
+}
@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"fmt"
+
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
 )
 
 func GetUserId(c *gin.Context) (u64 uint64) {
@@ -11,9 +16,65 @@ func GetUserId(c *gin.Context) (u64 uint64) {
 	return
 }
 
+// GetUserInformation returns the info of token, the values which are not string are formatted, use GetUserInfo to get the original values.
 func GetUserInformation(c *gin.Context) (info map[string]string) {
+
+	values := GetUserInfo(c)
+	if values == nil {
+		return
+	}
+
+	info = make(map[string]string, len(values))
+	for key, value := range values {
+		if str, ok := value.(string); ok {
+			info[key] = str
+			continue
+		}
+		info[key] = fmt.Sprint(value)
+	}
+	return
+}
+
+func GetUserInfo(c *gin.Context) (info map[string]interface{}) {
 	if val, ok := c.Get(UserInformation); ok && val != nil {
-		info, _ = val.(map[string]string)
+		info, _ = val.(map[string]interface{})
+	}
+	return
+}
+
+// GetClaims returns the claims of token verified by authentication middleware, e.g. for auth.TokenManager.Logout.
+func GetClaims(c *gin.Context) (claims jwt.MapClaims) {
+	if val, ok := c.Get(Claims); ok && val != nil {
+		claims, _ = val.(jwt.MapClaims)
 	}
 	return
 }
+
+// GetUserClaims returns the claims struct registered by auth.RegisterClaims, e.g. GetUserClaims(c).(*ShopClaims)
+func GetUserClaims(c *gin.Context) (claims auth.UserClaims) {
+	if val, ok := c.Get(UserClaims); ok && val != nil {
+		claims, _ = val.(auth.UserClaims)
+	}
+	return
+}
+
+func GetTenant(c *gin.Context) string {
+	if claims := GetUserClaims(c); claims != nil {
+		return claims.GetTenant()
+	}
+	return ""
+}
+
+func GetRoles(c *gin.Context) []string {
+	if claims := GetUserClaims(c); claims != nil {
+		return claims.GetRoles()
+	}
+	return nil
+}
+
+func HasRole(c *gin.Context, role string) bool {
+	if claims := GetUserClaims(c); claims != nil {
+		return claims.HasRole(role)
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/codec"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/auth"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+const (
+	DefaultTTL = time.Minute
+
+	// StatusHeader tells whether the response is from cache, HIT or MISS
+	StatusHeader = "X-Cache"
+)
+
+// not cached headers, they are different in every response
+var excludedHeaders = []string{requestid.Header, "Set-Cookie", "Date", StatusHeader}
+
+type Config struct {
+	Store       Store // responses are not cached without store, only ETag and If-None-Match work
+	TTL         time.Duration
+	VaryQuery   bool
+	VaryUser    bool // user id is set by auth.UserJwtAuthentication, so it should be registered before this
+	VaryHeaders []string
+	Tags        []string
+	KeyFunc     func(c *gin.Context) string // replaces the key generated by path, query, user and headers, the format negotiated is still appended
+}
+
+type Option func(config *Config)
+
+func CacheStore(store Store) Option {
+	return func(config *Config) {
+		config.Store = store
+	}
+}
+
+func CacheTTL(ttl time.Duration) Option {
+	return func(config *Config) {
+		config.TTL = ttl
+	}
+}
+
+func CacheVaryQuery(vary bool) Option {
+	return func(config *Config) {
+		config.VaryQuery = vary
+	}
+}
+
+func CacheVaryUser(vary bool) Option {
+	return func(config *Config) {
+		config.VaryUser = vary
+	}
+}
+
+func CacheVaryHeaders(headers ...string) Option {
+	return func(config *Config) {
+		config.VaryHeaders = append(config.VaryHeaders, headers...)
+	}
+}
+
+// CacheTags tags the cached responses, write handlers invalidate them by Invalidate or InvalidateTags.
+func CacheTags(tags ...string) Option {
+	return func(config *Config) {
+		config.Tags = append(config.Tags, tags...)
+	}
+}
+
+func CacheKeyFunc(keyFunc func(c *gin.Context) string) Option {
+	return func(config *Config) {
+		config.KeyFunc = keyFunc
+	}
+}
+
+// Cache responds GET and HEAD requests from store, and sets ETag of successful responses,
+// 304 is responded if the ETag matches If-None-Match.
+// It's used for routes by middleware.WithPath or as route handler, the responses of the route are buffered,
+// so don't use it for streaming responses.
+func Cache(options ...Option) gin.HandlerFunc {
+
+	config := &Config{
+		TTL:       DefaultTTL,
+		VaryQuery: true,
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return config.handle
+}
+
+// ETag only responds 304 for not modified responses, the responses are not cached.
+func ETag() gin.HandlerFunc {
+	return Cache()
+}
+
+// InvalidateTags deletes the cached responses of tags after write handlers succeeded.
+func InvalidateTags(store Store, tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		Invalidate(c, store, tags...)
+	}
+}
+
+// Invalidate deletes the cached responses of tags, the error is logged only,
+// because the write has been done and the cache will expire.
+func Invalidate(c *gin.Context, store Store, tags ...string) {
+
+	if err := store.Invalidate(c.Request.Context(), tags...); err != nil {
+		logrus.WithError(err).
+			WithField("reqId", requestid.GetRequestId(c)).
+			WithField("tags", tags).
+			Warn("invalidate response cache failed")
+	}
+}
+
+func (config *Config) handle(c *gin.Context) {
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Next()
+		return
+	}
+
+	// the response is rendered in the format negotiated by Accept, see response.Render
+	addVary(c.Writer.Header(), append([]string{"Accept"}, config.VaryHeaders...)...)
+
+	var key string
+	if config.Store != nil {
+
+		key = config.GetKey(c)
+
+		entry, err := config.Store.Get(c.Request.Context(), key)
+		if err != nil {
+			logrus.WithError(err).WithField("reqId", requestid.GetRequestId(c)).Warn("get response cache failed")
+		}
+
+		if entry != nil {
+			c.Header(StatusHeader, "HIT")
+			writeEntry(c, entry)
+			c.Abort()
+			return
+		}
+	}
+
+	writer := newBufferWriter(c.Writer)
+	c.Writer = writer
+
+	// restore the writer if handler panics, so the response of recovery is written to client instead of the buffer
+	defer func() {
+		c.Writer = writer.ResponseWriter
+	}()
+
+	c.Next()
+
+	c.Writer = writer.ResponseWriter
+
+	entry := &Entry{
+		Status: writer.status,
+		Body:   writer.body.Bytes(),
+	}
+
+	if entry.Status == http.StatusOK && c.Writer.Header().Get("ETag") == "" {
+		c.Header("ETag", generateETag(entry.Body))
+	}
+
+	if config.Store != nil && isCacheable(entry.Status, c.Writer.Header()) {
+
+		c.Header(StatusHeader, "MISS")
+		entry.Header = middleware.CloneHeader(c.Writer.Header(), excludedHeaders...)
+
+		if err := config.Store.Set(c.Request.Context(), key, entry, config.TTL, config.Tags); err != nil {
+			logrus.WithError(err).WithField("reqId", requestid.GetRequestId(c)).Warn("set response cache failed")
+		}
+	}
+
+	writeEntry(c, entry)
+}
+
+// GetKey returns the cache key of request, it's the hash of path, format negotiated, sorted query, user id and headers by config.
+func (config *Config) GetKey(c *gin.Context) string {
+
+	format := string(codec.Negotiate(c))
+
+	if config.KeyFunc != nil {
+		return config.KeyFunc(c) + ":" + format
+	}
+
+	parts := []string{c.Request.URL.Path, "format:" + format}
+
+	if config.VaryQuery {
+		// Encode sorts by key, so the same query in different order has the same key
+		parts = append(parts, c.Request.URL.Query().Encode())
+	}
+
+	if config.VaryUser {
+		parts = append(parts, "user:"+strconv.FormatUint(auth.GetUserId(c), 10))
+	}
+
+	headers := append([]string{}, config.VaryHeaders...)
+	sort.Strings(headers)
+	for _, header := range headers {
+		parts = append(parts, http.CanonicalHeaderKey(header)+":"+c.GetHeader(header))
+	}
+
+	hash := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+func writeEntry(c *gin.Context, entry *Entry) {
+
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		header[name] = values
+	}
+
+	if entry.Status == http.StatusOK && matchETag(c.GetHeader("If-None-Match"), header.Get("ETag")) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Writer.WriteHeader(entry.Status)
+	if len(entry.Body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	if _, err := c.Writer.Write(entry.Body); err != nil {
+		logrus.WithError(err).WithField("reqId", requestid.GetRequestId(c)).Debug("write response failed")
+	}
+}
+
+// addVary appends the headers to Vary if not listed
+func addVary(header http.Header, names ...string) {
+
+	listed := make(map[string]bool)
+	for _, value := range header["Vary"] {
+		for _, name := range strings.Split(value, ",") {
+			listed[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if !listed[name] {
+			header.Add("Vary", name)
+			listed[name] = true
+		}
+	}
+}
+
+func generateETag(body []byte) string {
+
+	hash := sha1.Sum(body)
+	return `"` + hex.EncodeToString(hash[:]) + `"`
+}
+
+// matchETag uses weak comparison as If-None-Match required
+func matchETag(ifNoneMatch, etag string) bool {
+
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isCacheable(status int, header http.Header) bool {
+
+	if status != http.StatusOK || header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	return !strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-store")
+}
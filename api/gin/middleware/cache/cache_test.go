@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/auth"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/recovery"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+)
+
+type memoryStore struct {
+	lock    sync.Mutex
+	entries map[string]*Entry
+	tags    map[string][]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: map[string]*Entry{}, tags: map[string][]string{}}
+}
+
+func (store *memoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.entries[key], nil
+}
+
+func (store *memoryStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.entries[key] = entry
+	for _, tag := range tags {
+		store.tags[tag] = append(store.tags[tag], key)
+	}
+
+	return nil
+}
+
+func (store *memoryStore) Invalidate(ctx context.Context, tags ...string) error {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	for _, tag := range tags {
+		for _, key := range store.tags[tag] {
+			delete(store.entries, key)
+		}
+		delete(store.tags, tag)
+	}
+
+	return nil
+}
+
+func serve(engine *gin.Engine, method, target string, header http.Header) *httptest.ResponseRecorder {
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	engine.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestETag(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.WithPath(ETag(), "/products"))
+
+	engine.GET("/products", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"name": "product"})
+	})
+	engine.GET("/orders", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"name": "order"})
+	})
+	engine.GET("/products/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{})
+	})
+
+	resp := serve(engine, http.MethodGet, "/products", nil)
+	etag := resp.Header().Get("ETag")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+	assert.Equal(t, `{"name":"product"}`, resp.Body.String())
+	assert.Empty(t, resp.Header().Get(StatusHeader))
+
+	resp = serve(engine, http.MethodGet, "/products", http.Header{"If-None-Match": {`"other", W/` + etag}})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, etag, resp.Header().Get("ETag"))
+
+	resp = serve(engine, http.MethodGet, "/products", http.Header{"If-None-Match": {`"other"`}})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = serve(engine, http.MethodGet, "/orders", nil)
+	assert.Empty(t, resp.Header().Get("ETag"))
+	assert.Empty(t, resp.Header().Get("Vary"))
+
+	resp = serve(engine, http.MethodGet, "/products/missing", http.Header{"If-None-Match": {"*"}})
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Empty(t, resp.Header().Get("ETag"))
+}
+
+func TestCache(t *testing.T) {
+
+	store := newMemoryStore()
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/products", Cache(CacheStore(store), CacheTags("products")), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"page": c.Query("page"), "calls": calls})
+	})
+	engine.POST("/products", InvalidateTags(store, "products"), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	resp := serve(engine, http.MethodGet, "/products?page=1&size=10", nil)
+	assert.Equal(t, "MISS", resp.Header().Get(StatusHeader))
+	assert.Equal(t, `{"calls":1,"page":"1"}`, resp.Body.String())
+
+	resp = serve(engine, http.MethodGet, "/products?size=10&page=1", nil)
+	assert.Equal(t, "HIT", resp.Header().Get(StatusHeader))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `{"calls":1,"page":"1"}`, resp.Body.String())
+
+	resp = serve(engine, http.MethodGet, "/products?page=1&size=10", http.Header{"If-None-Match": {resp.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	resp = serve(engine, http.MethodGet, "/products?page=2", nil)
+	assert.Equal(t, "MISS", resp.Header().Get(StatusHeader))
+	assert.Equal(t, `{"calls":2,"page":"2"}`, resp.Body.String())
+
+	resp = serve(engine, http.MethodPost, "/products", nil)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = serve(engine, http.MethodGet, "/products?page=1&size=10", nil)
+	assert.Equal(t, "MISS", resp.Header().Get(StatusHeader))
+	assert.Equal(t, `{"calls":3,"page":"1"}`, resp.Body.String())
+}
+
+func TestCache_Accept(t *testing.T) {
+
+	store := newMemoryStore()
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/products", Cache(CacheStore(store), CacheVaryHeaders("Accept-Language")), func(c *gin.Context) {
+		calls++
+		response.Data(c, calls)
+	})
+
+	xml := http.Header{"Accept": {"application/xml"}}
+	json := http.Header{"Accept": {"application/json"}}
+
+	resp := serve(engine, http.MethodGet, "/products", xml)
+	assert.Equal(t, "MISS", resp.Header().Get(StatusHeader))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/xml")
+	assert.Equal(t, []string{"Accept", "Accept-Language"}, resp.Header()["Vary"])
+
+	resp = serve(engine, http.MethodGet, "/products", json)
+	assert.Equal(t, "MISS", resp.Header().Get(StatusHeader))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, resp.Body.String(), `"data":2`)
+
+	resp = serve(engine, http.MethodGet, "/products", json)
+	assert.Equal(t, "HIT", resp.Header().Get(StatusHeader))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, resp.Body.String(), `"data":2`)
+	assert.Equal(t, []string{"Accept", "Accept-Language"}, resp.Header()["Vary"])
+
+	resp = serve(engine, http.MethodGet, "/products", xml)
+	assert.Equal(t, "HIT", resp.Header().Get(StatusHeader))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/xml")
+}
+
+func TestCache_NotCacheable(t *testing.T) {
+
+	store := newMemoryStore()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Cache(CacheStore(store)))
+	engine.GET("/error", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{})
+	})
+	engine.GET("/cookie", func(c *gin.Context) {
+		c.SetCookie("session", "value", 0, "/", "", http.SameSiteDefaultMode, false, true)
+		c.String(http.StatusOK, "cookie")
+	})
+	engine.GET("/no-store", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.String(http.StatusOK, "no-store")
+	})
+
+	for _, path := range []string{"/error", "/cookie", "/no-store"} {
+		serve(engine, http.MethodGet, path, nil)
+	}
+
+	assert.Empty(t, store.entries)
+}
+
+func TestCache_Panic(t *testing.T) {
+
+	store := newMemoryStore()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(recovery.Recovery(recovery.Debug(false)))
+	engine.GET("/panic", Cache(CacheStore(store)), func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("failed")
+	})
+
+	resp := serve(engine, http.MethodGet, "/panic", nil)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":500000`)
+	assert.NotContains(t, resp.Body.String(), "partial")
+	assert.Empty(t, store.entries)
+}
+
+func TestConfig_GetKey(t *testing.T) {
+
+	newContext := func(target string, userId uint64, header http.Header) *gin.Context {
+
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		ctx.Request.Header = header
+		if userId > 0 {
+			ctx.Set(auth.UserID, userId)
+		}
+
+		return ctx
+	}
+
+	tests := []struct {
+		options []Option
+		a       *gin.Context
+		b       *gin.Context
+		same    bool
+	}{
+		{
+			a:    newContext("/a?x=1&y=2", 0, nil),
+			b:    newContext("/a?y=2&x=1", 0, nil),
+			same: true,
+		},
+		{
+			a:    newContext("/a?x=1", 0, nil),
+			b:    newContext("/a?x=2", 0, nil),
+			same: false,
+		},
+		{
+			options: []Option{CacheVaryQuery(false)},
+			a:       newContext("/a?x=1", 0, nil),
+			b:       newContext("/a?x=2", 0, nil),
+			same:    true,
+		},
+		{
+			a:    newContext("/a", 1, nil),
+			b:    newContext("/a", 2, nil),
+			same: true,
+		},
+		{
+			options: []Option{CacheVaryUser(true)},
+			a:       newContext("/a", 1, nil),
+			b:       newContext("/a", 2, nil),
+			same:    false,
+		},
+		{
+			options: []Option{CacheVaryHeaders("Accept-Language")},
+			a:       newContext("/a", 0, http.Header{"Accept-Language": {"en"}}),
+			b:       newContext("/a", 0, http.Header{"Accept-Language": {"zh-CN"}}),
+			same:    false,
+		},
+		{
+			options: []Option{CacheKeyFunc(func(c *gin.Context) string { return "fixed" })},
+			a:       newContext("/a", 0, nil),
+			b:       newContext("/b", 0, nil),
+			same:    true,
+		},
+		{
+			a:    newContext("/a", 0, http.Header{"Accept": {"application/xml"}}),
+			b:    newContext("/a", 0, http.Header{"Accept": {"application/json"}}),
+			same: false,
+		},
+		{
+			a:    newContext("/a", 0, http.Header{"Accept": {"application/json"}}),
+			b:    newContext("/a", 0, http.Header{"Accept": {"*/*"}}),
+			same: true,
+		},
+		{
+			options: []Option{CacheKeyFunc(func(c *gin.Context) string { return "fixed" })},
+			a:       newContext("/a", 0, http.Header{"Accept": {"application/xml"}}),
+			b:       newContext("/a", 0, http.Header{"Accept": {"application/json"}}),
+			same:    false,
+		},
+	}
+
+	for index, test := range tests {
+
+		config := &Config{VaryQuery: true}
+		for _, option := range test.options {
+			option(config)
+		}
+
+		assert.Equal(t, test.same, config.GetKey(test.a) == config.GetKey(test.b), index)
+	}
+}
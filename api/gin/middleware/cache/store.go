@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	dataCache "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+)
+
+const (
+	DefaultKeyPrefix = "httpcache:"
+	DefaultTagTTL    = 24 * time.Hour
+)
+
+// Entry is the cached response
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store saves the responses, Get returns nil entry without error when the key is not cached.
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// RedisStore saves responses in the redis connection of data/cache pool,
+// the keys of responses tagged by a tag are saved in a set, so they can be deleted by tag.
+type RedisStore struct {
+	connectionKey string
+	prefix        string
+	tagTTL        time.Duration
+}
+
+type RedisStoreOption func(store *RedisStore)
+
+func RedisStorePrefix(prefix string) RedisStoreOption {
+	return func(store *RedisStore) {
+		store.prefix = prefix
+	}
+}
+
+// RedisStoreTagTTL sets the expiration of tag sets, it should be longer than the TTL of responses.
+func RedisStoreTagTTL(ttl time.Duration) RedisStoreOption {
+	return func(store *RedisStore) {
+		store.tagTTL = ttl
+	}
+}
+
+func NewRedisStore(connectionKey string, options ...RedisStoreOption) *RedisStore {
+
+	store := &RedisStore{
+		connectionKey: connectionKey,
+		prefix:        DefaultKeyPrefix,
+		tagTTL:        DefaultTagTTL,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+func (store *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := conn.Get(ctx, store.entryKey(key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{}
+	if err = json.Unmarshal(value, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (store *RedisStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	entryKey := store.entryKey(key)
+
+	_, err = conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+
+		pipe.Set(ctx, entryKey, value, ttl)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, store.tagKey(tag), entryKey)
+			pipe.Expire(ctx, store.tagKey(tag), store.tagTTL)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// Invalidate deletes the responses tagged by any of tags.
+func (store *RedisStore) Invalidate(ctx context.Context, tags ...string) error {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+
+		tagKey := store.tagKey(tag)
+
+		keys, err := conn.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+
+		if err = conn.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (store *RedisStore) entryKey(key string) string {
+	return store.prefix + "entry:" + key
+}
+
+func (store *RedisStore) tagKey(tag string) string {
+	return store.prefix + "tag:" + tag
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// CloneHeader deep copies the response header without the excluded ones,
+// it's used by the middlewares which save responses to replay them later.
+func CloneHeader(header http.Header, excluded ...string) http.Header {
+
+	cloned := make(http.Header, len(header))
+	for name, values := range header {
+		cloned[name] = append([]string{}, values...)
+	}
+
+	for _, name := range excluded {
+		cloned.Del(name)
+	}
+
+	return cloned
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneHeader(t *testing.T) {
+
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Date":         {"Mon, 19 Oct 2026 00:00:00 GMT"},
+		"X-Request-Id": {"id"},
+	}
+
+	cloned := CloneHeader(header, "Date", "x-request-id")
+	assert.Equal(t, http.Header{"Content-Type": {"application/json"}}, cloned)
+
+	cloned["Content-Type"][0] = "text/plain"
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Len(t, header, 3)
+}
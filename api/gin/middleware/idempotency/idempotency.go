@@ -0,0 +1,368 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	ginMiddleware "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/auth"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/idcreator/snowflake"
+)
+
+const (
+	DefaultHeader  = "Idempotency-Key"
+	DefaultTTL     = 24 * time.Hour
+	DefaultLockTTL = time.Minute
+	// DefaultMaxBodySize limits the body read for fingerprint, it's the default max receive message size of grpc
+	DefaultMaxBodySize = 4 << 20
+
+	// ReplayedHeader is set to true when the response is replayed
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// not replayed headers, they are different in every response
+var excludedHeaders = []string{requestid.Header, "Date"}
+
+var errorBodyTooLarge = stderrors.New("request body too large")
+
+type Config struct {
+	Header      string
+	TTL         time.Duration // how long the response is replayed
+	LockTTL     time.Duration // the lock is extended while the request is processing and released when completed, it's the timeout if the process crashed
+	Required    bool          // responds param error if the request has no key, otherwise the request is processed as usual
+	Methods     []string
+	MaxBodySize int64 // the request with larger body is responded 413
+}
+
+type Option func(config *Config)
+
+func IdempotencyHeader(header string) Option {
+	return func(config *Config) {
+		config.Header = header
+	}
+}
+
+func IdempotencyTTL(ttl, lockTTL time.Duration) Option {
+	return func(config *Config) {
+		config.TTL = ttl
+		config.LockTTL = lockTTL
+	}
+}
+
+func IdempotencyRequired(required bool) Option {
+	return func(config *Config) {
+		config.Required = required
+	}
+}
+
+func IdempotencyMaxBodySize(size int64) Option {
+	return func(config *Config) {
+		if size > 0 {
+			config.MaxBodySize = size
+		}
+	}
+}
+
+// IdempotencyMethods sets the methods checked, default is POST and PATCH.
+func IdempotencyMethods(methods ...string) Option {
+	return func(config *Config) {
+		config.Methods = methods
+	}
+}
+
+type idempotency struct {
+	config  Config
+	store   Store
+	methods map[string]bool
+}
+
+// Idempotency replays the response of the request with the same Idempotency-Key,
+// the key is scoped by user id if auth.UserJwtAuthentication is registered before it.
+// The request is responded with errors.IdempotencyKeyProcessing if the previous one is processing,
+// and errors.IdempotencyKeyReused if the key is used by a different request (method, uri or body).
+// The response of server error is not saved, so the client can retry it.
+func Idempotency(store Store, options ...Option) gin.HandlerFunc {
+
+	config := Config{
+		Header:      DefaultHeader,
+		TTL:         DefaultTTL,
+		LockTTL:     DefaultLockTTL,
+		Methods:     []string{http.MethodPost, http.MethodPatch},
+		MaxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	middleware := &idempotency{
+		config:  config,
+		store:   store,
+		methods: make(map[string]bool, len(config.Methods)),
+	}
+
+	for _, method := range config.Methods {
+		middleware.methods[method] = true
+	}
+
+	return middleware.handle
+}
+
+func (middleware *idempotency) handle(c *gin.Context) {
+
+	if !middleware.methods[c.Request.Method] {
+		c.Next()
+		return
+	}
+
+	idempotencyKey := c.GetHeader(middleware.config.Header)
+	if idempotencyKey == "" {
+		if middleware.config.Required {
+			c.Abort()
+			response.Error(c, errors.RequestParamError.WithMessagef("header %s is required", middleware.config.Header))
+			return
+		}
+
+		c.Next()
+		return
+	}
+
+	logger := logrus.WithField("reqId", requestid.GetRequestId(c)).WithField("idempotencyKey", idempotencyKey)
+
+	fingerprint, err := getFingerprint(c, middleware.config.MaxBodySize)
+	if err == errorBodyTooLarge {
+		c.Abort()
+		response.Error(c, errors.RequestEntityTooLarge)
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Warn("read request body failed")
+		c.Abort()
+		response.Error(c, errors.RequestParamError)
+		return
+	}
+
+	key := strconv.FormatUint(auth.GetUserId(c), 10) + ":" + idempotencyKey
+
+	// the token identifies the request holding the lock, so it doesn't change the record after the lock expired
+	token := strconv.FormatUint(snowflake.NextID(), 10)
+
+	locked, err := middleware.store.Lock(c.Request.Context(), key, &Record{Token: token, Fingerprint: fingerprint}, middleware.config.LockTTL)
+	if err != nil {
+		logger.WithError(err).Error("lock idempotency key failed")
+		c.Abort()
+		response.Error(c, errors.ServiceUnavailable)
+		return
+	}
+
+	if !locked {
+		c.Abort()
+		middleware.replay(c, logger, key, fingerprint)
+		return
+	}
+
+	writer := &captureWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	stopRefresh := middleware.refreshLock(logger, key, token)
+
+	completed := false
+	defer func() {
+		// release the lock if handler panics, so the request can be retried
+		if !completed {
+			stopRefresh()
+			middleware.release(logger, key, token)
+		}
+	}()
+
+	c.Next()
+	completed = true
+
+	// stop refreshing before saving, otherwise the ttl of the response may be reset to LockTTL
+	stopRefresh()
+
+	c.Writer = writer.ResponseWriter
+
+	if c.Writer.Status() >= http.StatusInternalServerError {
+		middleware.release(logger, key, token)
+		return
+	}
+
+	record := &Record{
+		Token:       token,
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      c.Writer.Status(),
+		Header:      ginMiddleware.CloneHeader(c.Writer.Header(), excludedHeaders...),
+		Body:        writer.body.Bytes(),
+	}
+
+	// the request context may be canceled if client disconnected, the response should be saved anyway
+	saved, err := middleware.store.Save(context.Background(), key, record, middleware.config.TTL)
+	if err != nil {
+		logger.WithError(err).Error("save idempotent response failed")
+		return
+	}
+
+	if !saved {
+		logger.Warn("idempotency lock expired and taken by another request, the response is not saved")
+	}
+}
+
+func (middleware *idempotency) replay(c *gin.Context, logger *logrus.Entry, key, fingerprint string) {
+
+	record, err := middleware.store.Get(c.Request.Context(), key)
+	if err != nil {
+		logger.WithError(err).Error("get idempotent response failed")
+		response.Error(c, errors.ServiceUnavailable)
+		return
+	}
+
+	if record == nil {
+		// the previous request completed with server error and released the key just now
+		response.Error(c, errors.IdempotencyKeyProcessing)
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		logger.Warn("idempotency key is reused with a different request")
+		response.Error(c, errors.IdempotencyKeyReused)
+		return
+	}
+
+	if !record.Completed {
+		response.Error(c, errors.IdempotencyKeyProcessing)
+		return
+	}
+
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(ReplayedHeader, "true")
+
+	c.Writer.WriteHeader(record.Status)
+	if len(record.Body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	if _, err := c.Writer.Write(record.Body); err != nil {
+		logger.WithError(err).Debug("write replayed response failed")
+	}
+}
+
+// refreshLock extends the lock every third of LockTTL until the returned function is called,
+// so the lock is not expired while a slow handler is still processing.
+func (middleware *idempotency) refreshLock(logger *logrus.Entry, key, token string) func() {
+
+	interval := middleware.config.LockTTL / 3
+	if interval <= 0 {
+		return func() {}
+	}
+
+	stopping := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopping:
+				return
+			case <-ticker.C:
+			}
+
+			extended, err := middleware.store.Extend(context.Background(), key, token, middleware.config.LockTTL)
+			if err != nil {
+				logger.WithError(err).Warn("extend idempotency lock failed")
+				continue
+			}
+
+			if !extended {
+				logger.Warn("idempotency lock expired before the request completed")
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stopping)
+		<-stopped
+	}
+}
+
+func (middleware *idempotency) release(logger *logrus.Entry, key, token string) {
+
+	deleted, err := middleware.store.Delete(context.Background(), key, token)
+	if err != nil {
+		logger.WithError(err).Error("release idempotency key failed")
+		return
+	}
+
+	if !deleted {
+		logger.Warn("idempotency lock expired and taken by another request, it's not released")
+	}
+}
+
+// getFingerprint hashes method, uri and body, and puts the body back for handlers.
+func getFingerprint(c *gin.Context, maxBodySize int64) (string, error) {
+
+	var body []byte
+	if c.Request.Body != nil {
+
+		var err error
+		body, err = ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			// MaxBytesReader returns the body of max size with its unexported error if the body is larger
+			if int64(len(body)) >= maxBodySize {
+				return "", errorBodyTooLarge
+			}
+			return "", err
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
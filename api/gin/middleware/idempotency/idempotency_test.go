@@ -0,0 +1,331 @@
+package idempotency
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+type memoryStore struct {
+	lock    sync.Mutex
+	records map[string]*Record
+	extends int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]*Record{}}
+}
+
+func (store *memoryStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if _, exist := store.records[key]; exist {
+		return false, nil
+	}
+
+	store.records[key] = record
+	return true, nil
+}
+
+func (store *memoryStore) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if record, exist := store.records[key]; !exist || record.Token != token {
+		return false, nil
+	}
+
+	store.extends++
+	return true, nil
+}
+
+func (store *memoryStore) getExtends() int {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.extends
+}
+
+func (store *memoryStore) Get(ctx context.Context, key string) (*Record, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.records[key], nil
+}
+
+func (store *memoryStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if current, exist := store.records[key]; !exist || current.Token != record.Token {
+		return false, nil
+	}
+
+	store.records[key] = record
+	return true, nil
+}
+
+func (store *memoryStore) Delete(ctx context.Context, key, token string) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if record, exist := store.records[key]; !exist || record.Token != token {
+		return false, nil
+	}
+
+	delete(store.records, key)
+	return true, nil
+}
+
+// expire deletes the record as its ttl expired
+func (store *memoryStore) expire(key string) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.records, key)
+}
+
+func serve(engine *gin.Engine, method, target, key, body string) *httptest.ResponseRecorder {
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(DefaultHeader, key)
+	}
+
+	engine.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestIdempotency(t *testing.T) {
+
+	store := newMemoryStore()
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Idempotency(store))
+	engine.POST("/orders", func(c *gin.Context) {
+		calls++
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.Header("X-Order", "created")
+		c.String(http.StatusCreated, "%s:%d", body, calls)
+	})
+
+	resp := serve(engine, http.MethodPost, "/orders", "key-1", "order")
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "order:1", resp.Body.String())
+	assert.Empty(t, resp.Header().Get(ReplayedHeader))
+
+	resp = serve(engine, http.MethodPost, "/orders", "key-1", "order")
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "order:1", resp.Body.String())
+	assert.Equal(t, "created", resp.Header().Get("X-Order"))
+	assert.Equal(t, "true", resp.Header().Get(ReplayedHeader))
+
+	resp = serve(engine, http.MethodPost, "/orders", "key-1", "other order")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":422000`)
+
+	resp = serve(engine, http.MethodPost, "/orders", "key-2", "order")
+	assert.Equal(t, "order:2", resp.Body.String())
+
+	resp = serve(engine, http.MethodPost, "/orders", "", "order")
+	assert.Equal(t, "order:3", resp.Body.String())
+}
+
+func TestIdempotency_Processing(t *testing.T) {
+
+	store := newMemoryStore()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Idempotency(store))
+	engine.POST("/payments", func(c *gin.Context) {
+		close(started)
+		<-finish
+		c.String(http.StatusOK, "paid")
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serve(engine, http.MethodPost, "/payments", "key", "")
+	}()
+
+	<-started
+	resp := serve(engine, http.MethodPost, "/payments", "key", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":409000`)
+
+	close(finish)
+	assert.Equal(t, "paid", (<-done).Body.String())
+}
+
+func TestIdempotency_RefreshLock(t *testing.T) {
+
+	store := newMemoryStore()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Idempotency(store, IdempotencyTTL(time.Hour, 30*time.Millisecond)))
+	engine.POST("/exports", func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "exported")
+	})
+
+	resp := serve(engine, http.MethodPost, "/exports", "key", "")
+	assert.Equal(t, "exported", resp.Body.String())
+
+	extends := store.getExtends()
+	assert.True(t, extends >= 2, extends)
+
+	record, _ := store.Get(context.Background(), "0:key")
+	assert.True(t, record.Completed)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, extends, store.getExtends(), "the lock is not extended after completed")
+}
+
+// the request whose lock expired doesn't change the record of the request holding the lock now
+func TestIdempotency_LockExpired(t *testing.T) {
+
+	store := newMemoryStore()
+	started := make(chan struct{}, 1)
+	finish := make(chan struct{})
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Idempotency(store, IdempotencyTTL(time.Hour, 0)))
+	engine.POST("/orders", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			started <- struct{}{}
+			<-finish
+		}
+		c.String(http.StatusOK, "order:%d", calls)
+	})
+	engine.POST("/payments", func(c *gin.Context) {
+		calls++
+		if calls == 3 {
+			started <- struct{}{}
+			<-finish
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, "payment:%d", calls)
+	})
+
+	for _, path := range []string{"/orders", "/payments"} {
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- serve(engine, http.MethodPost, path, path, "")
+		}()
+
+		<-started
+		store.expire("0:" + path)
+
+		resp := serve(engine, http.MethodPost, path, path, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		finish <- struct{}{}
+		<-done
+
+		record, _ := store.Get(context.Background(), "0:"+path)
+		assert.True(t, record.Completed, path)
+		assert.Equal(t, resp.Body.String(), string(record.Body), path)
+
+		replayed := serve(engine, http.MethodPost, path, path, "")
+		assert.Equal(t, resp.Body.String(), replayed.Body.String(), path)
+		assert.Equal(t, "true", replayed.Header().Get(ReplayedHeader), path)
+	}
+}
+
+func TestIdempotency_MaxBodySize(t *testing.T) {
+
+	store := newMemoryStore()
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Idempotency(store, IdempotencyMaxBodySize(16)))
+	engine.POST("/orders", func(c *gin.Context) {
+		calls++
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	resp := serve(engine, http.MethodPost, "/orders", "key-1", strings.Repeat("a", 16))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, strings.Repeat("a", 16), resp.Body.String())
+
+	resp = serve(engine, http.MethodPost, "/orders", "key-2", strings.Repeat("a", 17))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":413000`)
+	assert.Equal(t, 1, calls)
+
+	record, _ := store.Get(context.Background(), "0:key-2")
+	assert.Nil(t, record)
+}
+
+func TestIdempotency_ServerError(t *testing.T) {
+
+	store := newMemoryStore()
+	calls := 0
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		defer func() {
+			if recover() != nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	})
+	engine.Use(Idempotency(store, IdempotencyRequired(true)))
+	engine.POST("/orders", func(c *gin.Context) {
+		calls++
+		switch calls {
+		case 1:
+			c.Status(http.StatusServiceUnavailable)
+		case 2:
+			panic("failed")
+		default:
+			c.String(http.StatusOK, "ok")
+		}
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(engine, http.MethodPost, "/orders", "key", "").Code)
+	assert.Equal(t, http.StatusInternalServerError, serve(engine, http.MethodPost, "/orders", "key", "").Code)
+	assert.Equal(t, "ok", serve(engine, http.MethodPost, "/orders", "key", "").Body.String())
+	assert.Equal(t, 3, calls)
+
+	resp := serve(engine, http.MethodPost, "/orders", "", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":400000`)
+
+	_, ok := errors.Lookup(errors.CodeIdempotencyKeyReused)
+	assert.True(t, ok)
+}
@@ -0,0 +1,167 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	dataCache "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+)
+
+const DefaultKeyPrefix = "idempotency:"
+
+// Record is saved when the request starts, and the response is saved into it when the request completed.
+type Record struct {
+	Token       string      `json:"token"` // identifies the request holding the lock
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Store saves the records, Lock returns false if the key exists, Get returns nil without error if not exists.
+// Extend, Save and Delete change the record only if it has the token of the request holding the lock,
+// and return false if not, e.g. the lock expired and was taken by another request.
+type Store interface {
+	Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error)
+	Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string) (*Record, error)
+	// Save replaces the record of the same token
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key, token string) (bool, error)
+}
+
+// the record is compared by token before changed, so the lock of another request is not touched
+var extendScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value or cjson.decode(value).token ~= ARGV[1] then
+	return 0
+end
+return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+`)
+
+var saveScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value or cjson.decode(value).token ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+var deleteScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value or cjson.decode(value).token ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// RedisStore saves records in the redis connection of data/cache pool.
+type RedisStore struct {
+	connectionKey string
+	prefix        string
+}
+
+type RedisStoreOption func(store *RedisStore)
+
+func RedisStorePrefix(prefix string) RedisStoreOption {
+	return func(store *RedisStore) {
+		store.prefix = prefix
+	}
+}
+
+func NewRedisStore(connectionKey string, options ...RedisStoreOption) *RedisStore {
+
+	store := &RedisStore{
+		connectionKey: connectionKey,
+		prefix:        DefaultKeyPrefix,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+func (store *RedisStore) Lock(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+
+	return conn.SetNX(ctx, store.prefix+key, value, ttl).Result()
+}
+
+func (store *RedisStore) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := extendScript.Run(ctx, conn.Client, []string{store.prefix + key}, token, ttl.Milliseconds()).Int()
+	return result == 1, err
+}
+
+func (store *RedisStore) Get(ctx context.Context, key string) (*Record, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := conn.Get(ctx, store.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+	if err = json.Unmarshal(value, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (store *RedisStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := saveScript.Run(ctx, conn.Client, []string{store.prefix + key}, record.Token, value, ttl.Milliseconds()).Int()
+	return result == 1, err
+}
+
+func (store *RedisStore) Delete(ctx context.Context, key, token string) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := deleteScript.Run(ctx, conn.Client, []string{store.prefix + key}, token).Int()
+	return result == 1, err
+}
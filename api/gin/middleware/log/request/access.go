@@ -0,0 +1,416 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+type Format string
+
+const (
+	FormatDefault  Format = ""         // use the formatter of application logger
+	FormatJSON     Format = "json"     // one json object per line
+	FormatCombined Format = "combined" // apache combined log format, appends reqId and latency
+)
+
+const (
+	DefaultMaxBodySize = 4096
+	redactedValue      = "***"
+	truncatedSuffix    = "...(truncated)"
+)
+
+var (
+	DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	DefaultRedactFields  = []string{"password", "token", "secret", "authorization", "access_token"}
+)
+
+type AccessLogConfig struct {
+	Format              Format
+	Output              io.Writer // write access log to its own output, e.g. file, default is the output of application logger
+	SkipPaths           []string  // not log the paths, e.g. health check
+	SlowThreshold       time.Duration
+	VerySlowThreshold   time.Duration
+	CaptureHeaders      bool // logs the request headers, the headers of RedactHeaders are redacted
+	CaptureRequestBody  bool
+	CaptureResponseBody bool
+	MaxBodySize         int
+	RedactHeaders       []string // header names are case insensitive
+	RedactFields        []string // query parameters and json body fields, case insensitive
+}
+
+type Option func(config *AccessLogConfig)
+
+func AccessLogFormat(format Format) Option {
+	return func(config *AccessLogConfig) {
+		config.Format = format
+	}
+}
+
+func AccessLogOutput(output io.Writer) Option {
+	return func(config *AccessLogConfig) {
+		config.Output = output
+	}
+}
+
+func AccessLogSkipPaths(paths ...string) Option {
+	return func(config *AccessLogConfig) {
+		config.SkipPaths = append(config.SkipPaths, paths...)
+	}
+}
+
+// AccessLogSlowThresholds raises level to warning when latency exceeds slow, and to error when exceeds very slow.
+func AccessLogSlowThresholds(slow, verySlow time.Duration) Option {
+	return func(config *AccessLogConfig) {
+		config.SlowThreshold = slow
+		config.VerySlowThreshold = verySlow
+	}
+}
+
+func AccessLogCaptureHeaders(capture bool) Option {
+	return func(config *AccessLogConfig) {
+		config.CaptureHeaders = capture
+	}
+}
+
+func AccessLogCaptureBody(request, response bool, maxBodySize int) Option {
+	return func(config *AccessLogConfig) {
+		config.CaptureRequestBody = request
+		config.CaptureResponseBody = response
+		if maxBodySize > 0 {
+			config.MaxBodySize = maxBodySize
+		}
+	}
+}
+
+func AccessLogRedactHeaders(headers ...string) Option {
+	return func(config *AccessLogConfig) {
+		config.RedactHeaders = append(config.RedactHeaders, headers...)
+	}
+}
+
+func AccessLogRedactFields(fields ...string) Option {
+	return func(config *AccessLogConfig) {
+		config.RedactFields = append(config.RedactFields, fields...)
+	}
+}
+
+type accessLogger struct {
+	config        AccessLogConfig
+	entry         *logrus.Entry
+	skipPaths     map[string]bool
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+}
+
+func AccessLogger(entry *logrus.Entry, options ...Option) gin.HandlerFunc {
+
+	logger := newAccessLogger(entry, options...)
+
+	return logger.handle
+}
+
+func newAccessLogger(entry *logrus.Entry, options ...Option) *accessLogger {
+
+	config := AccessLogConfig{
+		MaxBodySize:   DefaultMaxBodySize,
+		RedactHeaders: append([]string{}, DefaultRedactHeaders...),
+		RedactFields:  append([]string{}, DefaultRedactFields...),
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if entry == nil {
+		entry = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	logger := &accessLogger{
+		config:        config,
+		entry:         entry,
+		skipPaths:     toSet(config.SkipPaths, false),
+		redactHeaders: toSet(config.RedactHeaders, true),
+		redactFields:  toSet(config.RedactFields, true),
+	}
+
+	if config.Format != FormatDefault || config.Output != nil {
+		logger.entry = newDedicatedEntry(entry, config)
+	}
+
+	return logger
+}
+
+// newDedicatedEntry creates a logger for access log only, so the application logger's formatter is not changed.
+func newDedicatedEntry(entry *logrus.Entry, config AccessLogConfig) *logrus.Entry {
+
+	dedicated := logrus.New()
+	dedicated.SetLevel(logrus.InfoLevel)
+	dedicated.SetOutput(entry.Logger.Out)
+	dedicated.SetFormatter(entry.Logger.Formatter)
+
+	if config.Output != nil {
+		dedicated.SetOutput(config.Output)
+	}
+
+	switch config.Format {
+	case FormatJSON:
+		dedicated.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	case FormatCombined:
+		dedicated.SetFormatter(&CombinedFormatter{})
+	}
+
+	return logrus.NewEntry(dedicated).WithFields(entry.Data)
+}
+
+func toSet(items []string, caseInsensitive bool) map[string]bool {
+
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if caseInsensitive {
+			item = strings.ToLower(item)
+		}
+		set[item] = true
+	}
+
+	return set
+}
+
+func (logger *accessLogger) handle(c *gin.Context) {
+
+	start := time.Now()
+
+	requestId := requestid.SetRequestIdIfNotExist(c)
+	requestid.InitContext(c)
+	c.Header(requestid.Header, requestId)
+
+	if logger.skipPaths[c.Request.URL.Path] {
+		c.Next()
+		return
+	}
+
+	var requestBody []byte
+	if logger.config.CaptureRequestBody {
+		requestBody = logger.captureRequestBody(c)
+	}
+
+	var writer *bodyCaptureWriter
+	if logger.config.CaptureResponseBody {
+		writer = &bodyCaptureWriter{ResponseWriter: c.Writer, limit: logger.config.MaxBodySize}
+		c.Writer = writer
+	}
+
+	c.Next()
+
+	latency := time.Since(start)
+
+	fields := logrus.Fields{
+		"reqId":      requestId,
+		"status":     c.Writer.Status(),
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"query":      logger.redactQuery(c.Request.URL.RawQuery),
+		"proto":      c.Request.Proto,
+		"size":       c.Writer.Size(),
+		"ip":         c.ClientIP(),
+		"latency":    latency,
+		"referer":    c.Request.Referer(),
+		"user-agent": c.Request.UserAgent(),
+		"startTime":  start,
+	}
+
+	if logger.config.CaptureHeaders {
+		fields["headers"] = logger.redactHeader(c.Request.Header)
+	}
+
+	if logger.config.CaptureRequestBody {
+		fields["requestBody"] = logger.redactBody(requestBody, c.ContentType())
+	}
+
+	if writer != nil {
+		fields["responseBody"] = logger.redactBody(writer.body.Bytes(), c.Writer.Header().Get("Content-Type"))
+	}
+
+	entry := logger.entry.WithFields(fields)
+	level := logger.getLevel(latency)
+
+	if len(c.Errors) > 0 {
+		entry.Log(level, c.Errors.String())
+	} else {
+		entry.Log(level)
+	}
+}
+
+func (logger *accessLogger) getLevel(latency time.Duration) logrus.Level {
+
+	if logger.config.VerySlowThreshold > 0 && latency >= logger.config.VerySlowThreshold {
+		return logrus.ErrorLevel
+	}
+
+	if logger.config.SlowThreshold > 0 && latency >= logger.config.SlowThreshold {
+		return logrus.WarnLevel
+	}
+
+	return logrus.InfoLevel
+}
+
+// captureRequestBody reads the head of body, and puts the read bytes back for handlers.
+func (logger *accessLogger) captureRequestBody(c *gin.Context) []byte {
+
+	if c.Request.Body == nil {
+		return nil
+	}
+
+	head, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, int64(logger.config.MaxBodySize)+1))
+	if err != nil {
+		return nil
+	}
+
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body),
+		Closer: c.Request.Body,
+	}
+
+	return head
+}
+
+func (logger *accessLogger) redactHeader(header map[string][]string) map[string]string {
+
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if logger.redactHeaders[strings.ToLower(name)] {
+			redacted[name] = redactedValue
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+
+	return redacted
+}
+
+func (logger *accessLogger) redactQuery(rawQuery string) string {
+
+	if rawQuery == "" {
+		return ""
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+
+	redacted := false
+	for name := range values {
+		if logger.redactFields[strings.ToLower(name)] {
+			values[name] = []string{redactedValue}
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return rawQuery
+	}
+
+	return values.Encode()
+}
+
+func (logger *accessLogger) redactBody(body []byte, contentType string) string {
+
+	truncated := len(body) > logger.config.MaxBodySize
+	if truncated {
+		body = body[:logger.config.MaxBodySize]
+	}
+
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.Contains(contentType, "json") && !truncated {
+
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err == nil {
+
+			redactedBytes, err := json.Marshal(logger.redactValue(data))
+			if err == nil {
+				return string(redactedBytes)
+			}
+		}
+	}
+
+	if truncated {
+		// the truncated json can not be redacted by fields, so it's not logged
+		if len(logger.redactFields) > 0 && strings.Contains(contentType, "json") {
+			return truncatedSuffix
+		}
+		return string(body) + truncatedSuffix
+	}
+
+	return string(body)
+}
+
+func (logger *accessLogger) redactValue(value interface{}) interface{} {
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if logger.redactFields[strings.ToLower(key)] {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = logger.redactValue(item)
+		}
+		return value
+	case []interface{}:
+		for index, item := range value {
+			value[index] = logger.redactValue(item)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture one more byte than limit, so it can be known whether the body is truncated
+func (w *bodyCaptureWriter) capture(data []byte) {
+
+	remain := w.limit + 1 - w.body.Len()
+	if remain <= 0 {
+		return
+	}
+
+	if len(data) > remain {
+		data = data[:remain]
+	}
+
+	w.body.Write(data)
+}
@@ -0,0 +1,169 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+func TestAccessLogger(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		options     []Option
+		path        string
+		body        string
+		handler     gin.HandlerFunc
+		wantLogged  bool
+		wantHeaders bool
+		want        map[string]interface{}
+	}{
+		{
+			options: []Option{AccessLogSkipPaths("/health")},
+			path:    "/health",
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			},
+			wantLogged: false,
+		},
+		{
+			path: "/test?token=abc&page=1",
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			},
+			wantLogged: true,
+			want: map[string]interface{}{
+				"level":  "info",
+				"path":   "/test",
+				"query":  "page=1&token=%2A%2A%2A",
+				"status": float64(http.StatusOK),
+				"reqId":  "test-request-id",
+			},
+		},
+		{
+			options: []Option{AccessLogSlowThresholds(time.Millisecond, time.Hour)},
+			path:    "/test",
+			handler: func(c *gin.Context) {
+				time.Sleep(2 * time.Millisecond)
+				c.String(http.StatusOK, "ok")
+			},
+			wantLogged: true,
+			want: map[string]interface{}{
+				"level": "warning",
+			},
+		},
+		{
+			options: []Option{AccessLogCaptureBody(true, true, 1024)},
+			path:    "/test",
+			body:    `{"username":"user","password":"123456"}`,
+			handler: func(c *gin.Context) {
+				body, _ := ioutil.ReadAll(c.Request.Body)
+				assert.Equal(t, `{"username":"user","password":"123456"}`, string(body))
+				c.JSON(http.StatusOK, gin.H{"token": "abc", "id": 1})
+			},
+			wantLogged: true,
+			want: map[string]interface{}{
+				"requestBody":  `{"password":"***","username":"user"}`,
+				"responseBody": `{"id":1,"token":"***"}`,
+			},
+		},
+		{
+			options: []Option{AccessLogCaptureBody(true, false, 4)},
+			path:    "/test",
+			body:    `plain text body`,
+			handler: func(c *gin.Context) {
+				body, _ := ioutil.ReadAll(c.Request.Body)
+				assert.Equal(t, `plain text body`, string(body))
+				c.String(http.StatusOK, "ok")
+			},
+			wantLogged: true,
+			want: map[string]interface{}{
+				"requestBody": "plai" + truncatedSuffix,
+			},
+		},
+		{
+			options: []Option{AccessLogCaptureHeaders(true)},
+			path:    "/test",
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			},
+			wantLogged:  true,
+			wantHeaders: true,
+			want: map[string]interface{}{
+				"requestBody": nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		output := &bytes.Buffer{}
+		options := append([]Option{AccessLogFormat(FormatJSON), AccessLogOutput(output)}, test.options...)
+
+		engine := gin.New()
+		engine.Use(AccessLogger(logrus.NewEntry(logrus.New()), options...))
+		engine.Any("/*path", test.handler)
+
+		req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		req.Header.Set(requestid.Header, "test-request-id")
+		req.Header.Set("Authorization", "secret-token")
+		if strings.HasPrefix(test.body, "{") {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		if !test.wantLogged {
+			assert.Empty(t, output.String())
+			continue
+		}
+
+		logged := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(output.Bytes(), &logged), output.String())
+
+		for key, value := range test.want {
+			assert.Equal(t, value, logged[key], key)
+		}
+
+		headers, ok := logged["headers"].(map[string]interface{})
+		assert.Equal(t, test.wantHeaders, ok)
+		if ok {
+			assert.Equal(t, redactedValue, headers["Authorization"])
+		}
+	}
+}
+
+func TestCombinedFormatter_Format(t *testing.T) {
+
+	startTime := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"ip":         "127.0.0.1",
+		"startTime":  startTime,
+		"method":     http.MethodGet,
+		"path":       "/ping",
+		"query":      "a=1",
+		"proto":      "HTTP/1.1",
+		"status":     http.StatusOK,
+		"size":       6,
+		"referer":    "",
+		"user-agent": "curl/7.64.1",
+		"reqId":      "abc",
+		"latency":    1500 * time.Microsecond,
+	})
+
+	formatted, err := (&CombinedFormatter{}).Format(entry)
+	assert.Nil(t, err)
+	assert.Equal(t, `127.0.0.1 - - [01/Jun/2020:08:00:00 +0000] "GET /ping?a=1 HTTP/1.1" 200 6 "-" "curl/7.64.1" abc 0.001500`+"\n", string(formatted))
+}
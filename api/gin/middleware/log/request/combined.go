@@ -0,0 +1,72 @@
+package request
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CombinedFormatter formats access log entry to apache combined log format:
+// %h - %u [%t] "%r" %>s %b "%{Referer}i" "%{User-agent}i" reqId latency
+type CombinedFormatter struct {
+}
+
+func (formatter *CombinedFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+
+	startTime, ok := entry.Data["startTime"].(time.Time)
+	if !ok {
+		startTime = entry.Time
+	}
+
+	requestLine := fmt.Sprintf("%v %v", entry.Data["method"], entry.Data["path"])
+	if query, ok := entry.Data["query"].(string); ok && query != "" {
+		requestLine = requestLine + "?" + query
+	}
+	if proto, ok := entry.Data["proto"].(string); ok && proto != "" {
+		requestLine = requestLine + " " + proto
+	}
+
+	size := fmt.Sprintf("%v", entry.Data["size"])
+	if value, ok := entry.Data["size"].(int); ok && value <= 0 {
+		size = "-"
+	}
+
+	latency := time.Duration(0)
+	if value, ok := entry.Data["latency"].(time.Duration); ok {
+		latency = value
+	}
+
+	buffer := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(buffer, "%s - %s [%s] %q %v %s %q %q %s %.6f\n",
+		valueOrDash(entry.Data["ip"]),
+		valueOrDash(entry.Data["user"]),
+		startTime.Format(combinedTimeFormat),
+		requestLine,
+		entry.Data["status"],
+		size,
+		valueOrDash(entry.Data["referer"]),
+		valueOrDash(entry.Data["user-agent"]),
+		valueOrDash(entry.Data["reqId"]),
+		latency.Seconds(),
+	)
+
+	return buffer.Bytes(), nil
+}
+
+func valueOrDash(value interface{}) string {
+
+	if value == nil {
+		return "-"
+	}
+
+	str := fmt.Sprintf("%v", value)
+	if str == "" {
+		return "-"
+	}
+
+	return str
+}
@@ -0,0 +1,167 @@
+package recovery
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/util/log"
+)
+
+// Reporter reports the recovered panic to error tracking system, e.g. sentry
+type Reporter interface {
+	Report(c *gin.Context, recovered interface{}, stack []byte)
+}
+
+type ReporterFunc func(c *gin.Context, recovered interface{}, stack []byte)
+
+func (f ReporterFunc) Report(c *gin.Context, recovered interface{}, stack []byte) {
+	f(c, recovered, stack)
+}
+
+type Option func(config *config)
+
+type config struct {
+	logger    *logrus.Entry
+	reporters []Reporter
+	debug     *bool
+}
+
+func Logger(logger *logrus.Entry) Option {
+	return func(config *config) {
+		if logger != nil {
+			config.logger = logger
+		}
+	}
+}
+
+func AddReporter(reporter Reporter) Option {
+	return func(config *config) {
+		if reporter != nil {
+			config.reporters = append(config.reporters, reporter)
+		}
+	}
+}
+
+// Debug responds the stack when enabled, default follows the gin mode.
+func Debug(enable bool) Option {
+	return func(config *config) {
+		config.debug = &enable
+	}
+}
+
+func (config *config) isDebug() bool {
+
+	if config.debug != nil {
+		return *config.debug
+	}
+
+	return gin.IsDebugging()
+}
+
+type StackData struct {
+	Panic string   `json:"panic"`
+	Stack []string `json:"stack"`
+}
+
+/* gin panic恢复中间件，以 ResponseData 格式返回错误 */
+func Recovery(options ...Option) gin.HandlerFunc {
+
+	conf := &config{
+		logger:    logrus.NewEntry(logrus.StandardLogger()),
+		reporters: make([]Reporter, 0),
+	}
+
+	for _, option := range options {
+		option(conf)
+	}
+
+	return func(c *gin.Context) {
+
+		defer func() {
+
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := debug.Stack()
+
+			entry := log.WithRequestId(c, conf.logger).
+				WithField("panic", recovered).
+				WithField("method", c.Request.Method).
+				WithField("path", c.Request.URL.Path)
+
+			if isBrokenPipe(recovered) {
+				entry.Warn("connection broken")
+				_ = c.Error(fmt.Errorf("%v", recovered))
+				c.Abort()
+				return
+			}
+
+			entry.WithField("stack", string(stack)).Error("panic recovered")
+
+			for _, reporter := range conf.reporters {
+				reportSafely(entry, reporter, c, recovered, stack)
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			data := response.ResponseData{
+				Code:    errors.ServerInternalError.Code(),
+				Message: errors.ServerInternalError.Message(),
+			}
+
+			if conf.isDebug() {
+				data.Data = StackData{
+					Panic: fmt.Sprintf("%v", recovered),
+					Stack: strings.Split(strings.TrimSpace(string(stack)), "\n"),
+				}
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, data)
+		}()
+
+		c.Next()
+	}
+}
+
+// reporter should not break the recovery
+func reportSafely(entry *logrus.Entry, reporter Reporter, c *gin.Context, recovered interface{}, stack []byte) {
+
+	defer func() {
+		if err := recover(); err != nil {
+			entry.WithField("reporterPanic", err).Error("report panic failed")
+		}
+	}()
+
+	reporter.Report(c, recovered, stack)
+}
+
+// 客户端断开连接时写入会 panic，这种情况不需要返回 500
+func isBrokenPipe(recovered interface{}) bool {
+
+	netError, ok := recovered.(*net.OpError)
+	if !ok {
+		return false
+	}
+
+	syscallError, ok := netError.Err.(*os.SyscallError)
+	if !ok {
+		return false
+	}
+
+	message := strings.ToLower(syscallError.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
@@ -0,0 +1,100 @@
+package recovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+func TestRecovery(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		debug      bool
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantBody   map[string]interface{}
+		wantReport bool
+		wantStack  bool
+	}{
+		{
+			debug: false,
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			debug: false,
+			handler: func(c *gin.Context) {
+				panic("something wrong")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody: map[string]interface{}{
+				"code": float64(errors.CodeServerInternalError),
+				"msg":  errors.ServerInternalError.Message(),
+			},
+			wantReport: true,
+		},
+		{
+			debug: true,
+			handler: func(c *gin.Context) {
+				panic("something wrong")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantReport: true,
+			wantStack:  true,
+		},
+	}
+
+	for _, test := range tests {
+
+		reported := false
+		engine := gin.New()
+		engine.Use(Recovery(
+			Debug(test.debug),
+			AddReporter(ReporterFunc(func(c *gin.Context, recovered interface{}, stack []byte) {
+				reported = true
+				assert.Equal(t, "something wrong", recovered)
+				assert.NotEmpty(t, stack)
+			})),
+			AddReporter(ReporterFunc(func(c *gin.Context, recovered interface{}, stack []byte) {
+				panic("reporter panic")
+			})),
+		))
+		engine.GET("/test", test.handler)
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(requestid.Header, "test-request-id")
+		engine.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.wantStatus, resp.Code)
+		assert.Equal(t, test.wantReport, reported)
+
+		if test.wantStatus == http.StatusOK {
+			continue
+		}
+
+		body := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+
+		if test.wantStack {
+			data, ok := body["data"].(map[string]interface{})
+			assert.True(t, ok)
+			assert.Equal(t, "something wrong", data["panic"])
+			assert.NotEmpty(t, data["stack"])
+			continue
+		}
+
+		assert.Equal(t, test.wantBody, body)
+	}
+}
@@ -3,21 +3,48 @@ package requestid
 import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 )
 
+// MetadataKey is the grpc metadata key of request id, metadata keys are lower case.
+const MetadataKey = "x-request-id"
+
+// GetRPCContext derives from the http request context, so the cancellation and deadline are kept,
+// and the request id is sent to rpc server through outgoing metadata.
 func GetRPCContext(ctx *gin.Context) context.Context {
 
 	requestId := GetRequestId(ctx)
-	rpcContext := context.WithValue(context.Background(), Key, requestId)
-	return rpcContext
+
+	return NewRPCContext(ctx.Request.Context(), requestId)
+}
+
+// NewRPCContext returns context carries request id in value and outgoing metadata.
+func NewRPCContext(ctx context.Context, requestId string) context.Context {
+
+	if requestId == "" {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, Key, requestId)
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(MetadataKey)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, requestId)
 }
 
 func GetRequestIdFromRPCContext(ctx context.Context) string {
 
-	requestId := ctx.Value(Key)
-	if requestId == nil {
-		return ""
+	if requestId, ok := ctx.Value(Key).(string); ok && requestId != "" {
+		return requestId
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKey); len(values) > 0 {
+			return values[0]
+		}
 	}
 
-	return requestId.(string)
+	return ""
 }
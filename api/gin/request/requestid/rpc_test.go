@@ -0,0 +1,63 @@
+package requestid
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGetRPCContext(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	deadline := time.Now().Add(time.Minute)
+	requestContext, cancel := context.WithDeadline(context.Background(), deadline)
+	c.Request = httptest.NewRequest("GET", "/test", nil).WithContext(requestContext)
+	c.Request.Header.Set(Header, "abc")
+
+	ctx := GetRPCContext(c)
+
+	actualDeadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, actualDeadline)
+	assert.Equal(t, "abc", GetRequestIdFromRPCContext(ctx))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"abc"}, md.Get(MetadataKey))
+
+	cancel()
+	assert.NotNil(t, ctx.Err())
+}
+
+func TestGetRequestIdFromRPCContext(t *testing.T) {
+
+	tests := []struct {
+		input context.Context
+		want  string
+	}{
+		{
+			input: context.Background(),
+			want:  "",
+		},
+		{
+			input: context.WithValue(context.Background(), Key, "abc"),
+			want:  "abc",
+		},
+		{
+			input: metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "def")),
+			want:  "def",
+		},
+	}
+
+	for _, test := range tests {
+
+		assert.Equal(t, test.want, GetRequestIdFromRPCContext(test.input))
+	}
+}
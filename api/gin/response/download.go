@@ -0,0 +1,183 @@
+package response
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+const (
+	DispositionAttachment = "attachment"
+	DispositionInline     = "inline"
+
+	streamBufferSize = 32 * 1024
+)
+
+// ContentDisposition returns the Content-Disposition header value, the filename is encoded by RFC 6266,
+// so non-ASCII filename (e.g. 中文) is supported, and old clients use the ASCII fallback filename.
+func ContentDisposition(disposition, filename string) string {
+
+	if filename == "" {
+		return disposition
+	}
+
+	fallback := asciiFilename(filename)
+	encoded := encodeFilename(filename)
+	if fallback == filename && encoded == filename {
+		return fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	}
+
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback, encoded)
+}
+
+// Attachment responds the content as a downloaded file, Range and If-Modified-Since requests are supported.
+func Attachment(c *gin.Context, filename string, content io.ReadSeeker, modTime time.Time) {
+	serveContent(c, DispositionAttachment, filename, content, modTime)
+}
+
+// Inline responds the content to be displayed in browser, Range requests are supported.
+func Inline(c *gin.Context, filename string, content io.ReadSeeker, modTime time.Time) {
+	serveContent(c, DispositionInline, filename, content, modTime)
+}
+
+// FileAttachment responds the local file as attachment, the base name of path is used if filename is empty.
+func FileAttachment(c *gin.Context, path, filename string) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Warn("open attachment file failed")
+		if os.IsNotExist(err) {
+			Error(c, errors.RequestPathError)
+			return
+		}
+		Error(c, errors.ServerInternalError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		logrus.WithError(err).WithField("path", path).Warn("attachment is not a file")
+		Error(c, errors.RequestPathError)
+		return
+	}
+
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+
+	Attachment(c, filename, file, info.ModTime())
+}
+
+// StreamAttachment copies the reader to response as attachment, it's used for the content generated while responding,
+// e.g. export of large data, so the length is unknown and Range is not supported.
+// ErrClientDisconnected is returned if the client closed the connection.
+func StreamAttachment(c *gin.Context, filename, contentType string, reader io.Reader) error {
+
+	requestId := setStreamHeaders(c)
+
+	if contentType == "" {
+		contentType = contentTypeByName(filename)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", ContentDisposition(DispositionAttachment, filename))
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	buffer := make([]byte, streamBufferSize)
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logrus.WithField("reqId", requestId).Debug("attachment stream client disconnected")
+			return ErrClientDisconnected
+		default:
+		}
+
+		n, readError := reader.Read(buffer)
+		if n > 0 {
+			if _, err := c.Writer.Write(buffer[:n]); err != nil {
+				logrus.WithError(err).WithField("reqId", requestId).Debug("write attachment stream failed")
+				return ErrClientDisconnected
+			}
+			c.Writer.Flush()
+		}
+
+		if readError == io.EOF {
+			return nil
+		}
+
+		if readError != nil {
+			logrus.WithError(readError).WithField("reqId", requestId).Error("read attachment stream failed")
+			return readError
+		}
+	}
+}
+
+func serveContent(c *gin.Context, disposition, filename string, content io.ReadSeeker, modTime time.Time) {
+
+	setStreamHeaders(c)
+
+	header := c.Writer.Header()
+	header.Set("Content-Disposition", ContentDisposition(disposition, filename))
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", contentTypeByName(filename))
+	}
+
+	http.ServeContent(c.Writer, c.Request, filename, modTime, content)
+}
+
+func contentTypeByName(filename string) string {
+
+	if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
+
+// asciiFilename replaces the characters which can't be used in quoted filename
+func asciiFilename(filename string) string {
+
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+}
+
+// encodeFilename percent-encodes the UTF-8 filename except the attr-char of RFC 5987
+func encodeFilename(filename string) string {
+
+	builder := strings.Builder{}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			builder.WriteByte(b)
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%%%02X", b))
+	}
+
+	return builder.String()
+}
+
+func isAttrChar(b byte) bool {
+
+	if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' {
+		return true
+	}
+
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,127 @@
+package response
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+func TestContentDisposition(t *testing.T) {
+
+	tests := []struct {
+		disposition string
+		filename    string
+		want        string
+	}{
+		{disposition: DispositionAttachment, filename: "", want: `attachment`},
+		{disposition: DispositionAttachment, filename: "report.csv", want: `attachment; filename="report.csv"`},
+		{disposition: DispositionInline, filename: "my report.pdf", want: `inline; filename="my report.pdf"; filename*=UTF-8''my%20report.pdf`},
+		{disposition: DispositionAttachment, filename: "报表.xlsx", want: `attachment; filename="__.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx`},
+		{disposition: DispositionAttachment, filename: `a"b.txt`, want: `attachment; filename="a_b.txt"; filename*=UTF-8''a%22b.txt`},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, ContentDisposition(test.disposition, test.filename), test.filename)
+	}
+}
+
+func TestAttachment(t *testing.T) {
+
+	tests := []struct {
+		rangeHeader string
+		wantStatus  int
+		wantBody    string
+	}{
+		{rangeHeader: "", wantStatus: http.StatusOK, wantBody: "0123456789"},
+		{rangeHeader: "bytes=2-5", wantStatus: http.StatusPartialContent, wantBody: "2345"},
+		{rangeHeader: "bytes=20-", wantStatus: http.StatusRequestedRangeNotSatisfiable, wantBody: ""},
+	}
+
+	for _, test := range tests {
+
+		resp := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		ctx, _ := gin.CreateTestContext(resp)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.rangeHeader != "" {
+			ctx.Request.Header.Set("Range", test.rangeHeader)
+		}
+
+		Attachment(ctx, "数据.pdf", strings.NewReader("0123456789"), time.Now())
+
+		assert.Equal(t, test.wantStatus, resp.Code, test.rangeHeader)
+		assert.Equal(t, `attachment; filename="__.pdf"; filename*=UTF-8''%E6%95%B0%E6%8D%AE.pdf`, resp.Header().Get("Content-Disposition"))
+		assert.NotEmpty(t, resp.Header().Get(requestid.Header))
+		if test.wantStatus != http.StatusRequestedRangeNotSatisfiable {
+			assert.Equal(t, "application/pdf", resp.Header().Get("Content-Type"))
+			assert.Equal(t, test.wantBody, resp.Body.String())
+		}
+	}
+}
+
+func TestFileAttachment(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "attachment")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "export.csv")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("id,name\n1,test\n"), 0644))
+
+	resp := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	FileAttachment(ctx, path, "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `attachment; filename="export.csv"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,name\n1,test\n", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	FileAttachment(ctx, filepath.Join(dir, "missing.csv"), "")
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestStreamAttachment(t *testing.T) {
+
+	resp := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set(requestid.Header, "request-id")
+
+	content := strings.Repeat("a", streamBufferSize*2+10)
+	assert.NoError(t, StreamAttachment(ctx, "导出.csv", "text/csv; charset=utf-8", strings.NewReader(content)))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "request-id", resp.Header().Get(requestid.Header))
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="__.csv"; filename*=UTF-8''%E5%AF%BC%E5%87%BA.csv`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, content, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(resp)
+	requestContext, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(requestContext)
+
+	assert.Equal(t, ErrClientDisconnected, StreamAttachment(ctx, "export.bin", "application/octet-stream", strings.NewReader(content)))
+	assert.Empty(t, resp.Body.String())
+}
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/i18n"
 )
 
 var (
@@ -18,10 +20,11 @@ var (
 )
 
 type ResponseData struct {
-	Status  int         `json:"-"`
-	Code    int         `json:"code"`
-	Data    interface{} `json:"data,omitempty"`
-	Message string      `json:"msg,omitempty"`
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Status  int         `json:"-" xml:"-"`
+	Code    int         `json:"code" xml:"code"`
+	Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	Message string      `json:"msg,omitempty" xml:"msg,omitempty"`
 }
 
 func (data ResponseData) Error() string {
@@ -67,6 +70,16 @@ func (data ResponseData) WithError(err error) *ResponseData {
 		data.Code = validationErrorData.Code
 		data.Message = validationErrorData.Message
 
+	case errors.Error:
+		data.Status = err.GetHTTPStatus()
+		data.Code = err.Code()
+		data.Message = err.Message()
+
+	case *errors.Error:
+		data.Status = err.GetHTTPStatus()
+		data.Code = err.Code()
+		data.Message = err.Message()
+
 	case validation.Errors:
 
 		data.Status = http.StatusBadRequest
@@ -119,11 +132,12 @@ func (data ResponseData) withValidationError(err error) *ResponseData {
 	return &data
 }
 
+// E responds error, the message is translated to the language of request if it's the default message of code.
 func E(c *gin.Context, httpStatus, errCode int, message string) {
 
-	c.JSON(httpStatus, ResponseData{
+	Render(c, httpStatus, ResponseData{
 		Code:    errCode,
-		Message: message,
+		Message: i18n.TranslateCode(i18n.GetLanguage(c), errCode, message),
 	})
 }
 
@@ -143,6 +157,10 @@ func TokenExpired(c *gin.Context) {
 	E(c, http.StatusUnauthorized, errors.CodeRequestTokenExpired, "request 'token' is expired")
 }
 
+func TokenRevoked(c *gin.Context) {
+	E(c, http.StatusUnauthorized, errors.CodeRequestTokenRevoked, "request 'token' is revoked")
+}
+
 func ParamErr(c *gin.Context, message string) {
 	E(c, http.StatusBadRequest, errors.CodeRequestParamError, message)
 }
@@ -171,39 +189,43 @@ func ResponseJSONP(c *gin.Context, data interface{}) {
 func Response(c *gin.Context, body interface{}) {
 
 	if c.Request.Method == "POST" {
-		c.JSON(http.StatusCreated, body)
+		Render(c, http.StatusCreated, body)
 		return
 	}
 	if c.Request.Method == "DELETE" {
-		c.JSON(http.StatusNoContent, body)
+		Render(c, http.StatusNoContent, body)
 		return
 	}
-	c.JSON(http.StatusOK, body)
+	Render(c, http.StatusOK, body)
 }
 
 func Error(c *gin.Context, e error) {
 
 	logrus.Tracef("response error: (%T)%v", e, e)
+
+	lang := i18n.GetLanguage(c)
+	e = i18n.LocalizeError(lang, e)
+
 	switch err := e.(type) {
 	case *ResponseData:
-		c.JSON(err.Status, err)
+		Render(c, err.Status, err.WithMessage(i18n.TranslateCode(lang, err.Code, err.Message)))
 
 	case ResponseData:
-		c.JSON(err.Status, err)
+		Render(c, err.Status, err.WithMessage(i18n.TranslateCode(lang, err.Code, err.Message)))
 
 	case validation.Error:
 
 		errorCode, convertError := strconv.ParseInt(err.Code(), 10, 64)
 		if convertError != nil {
 
-			c.JSON(http.StatusBadRequest, ResponseData{
+			Render(c, http.StatusBadRequest, ResponseData{
 				Code:    errors.CodeRequestParamError,
 				Message: err.Message(),
 			})
 			return
 		}
 
-		c.JSON(http.StatusBadRequest, ResponseData{
+		Render(c, http.StatusBadRequest, ResponseData{
 			Code:    int(errorCode),
 			Message: err.Message(),
 		})
@@ -212,7 +234,7 @@ func Error(c *gin.Context, e error) {
 
 		if len(err) > 1 {
 
-			c.JSON(http.StatusBadRequest, ResponseData{
+			Render(c, http.StatusBadRequest, ResponseData{
 				Code:    errors.CodeRequestParamError,
 				Message: err.Error(),
 			})
@@ -226,7 +248,16 @@ func Error(c *gin.Context, e error) {
 		}
 
 	default:
-		c.JSON(UnknownError.Status, &ResponseData{
+		// the error may wrap an errors.Error, e.g. fmt.Errorf("...: %w", errors.RequestParamError)
+		if businessError, ok := errors.FromError(e); ok {
+			Render(c, businessError.GetHTTPStatus(), ResponseData{
+				Code:    businessError.Code(),
+				Message: businessError.Message(),
+			})
+			return
+		}
+
+		Render(c, UnknownError.Status, &ResponseData{
 			Code:    UnknownError.Code,
 			Message: err.Error(),
 		})
@@ -11,6 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/stretchr/testify/assert"
+
+	apiErrors "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
 )
 
 func TestResponseData_Error(t *testing.T) {
@@ -412,6 +414,27 @@ func TestResponseError(t *testing.T) {
 				ResponseString string
 			}{HTTPStatus: http.StatusBadRequest, ResponseString: `{"code":400000,"msg":"password: password too short; username: username too short."}`},
 		},
+		{
+			Input: apiErrors.RequestTokenExpired,
+			Want: struct {
+				HTTPStatus     int
+				ResponseString string
+			}{HTTPStatus: http.StatusUnauthorized, ResponseString: `{"code":400003,"msg":"request 'token' is expired"}`},
+		},
+		{
+			Input: &apiErrors.ServiceUnavailable,
+			Want: struct {
+				HTTPStatus     int
+				ResponseString string
+			}{HTTPStatus: http.StatusServiceUnavailable, ResponseString: `{"code":503000,"msg":"Service Unavailable"}`},
+		},
+		{
+			Input: fmt.Errorf("load user: %w", apiErrors.RequestPathError.Wrap(errors.New("record not found"))),
+			Want: struct {
+				HTTPStatus     int
+				ResponseString string
+			}{HTTPStatus: http.StatusNotFound, ResponseString: `{"code":400001,"msg":"request path error"}`},
+		},
 	}
 
 	for _, test := range tests {
@@ -936,3 +959,36 @@ func TestParamInvalid(t *testing.T) {
 		assert.Equal(t, test.Want.ResponseString, resp.Body.String(), test)
 	}
 }
+
+func TestResponseError_Localized(t *testing.T) {
+
+	tests := []struct {
+		input          error
+		acceptLanguage string
+		want           string
+	}{
+		{input: apiErrors.RequestTokenExpired, acceptLanguage: "zh-CN", want: `{"code":400003,"msg":"请求token过期"}`},
+		{input: apiErrors.RequestTokenExpired, acceptLanguage: "en", want: `{"code":400003,"msg":"request 'token' is expired"}`},
+		{input: validation.Errors{"name": validation.ErrRequired}, acceptLanguage: "zh-CN", want: `{"code":400000,"msg":"不能为空"}`},
+		{input: &ResponseData{Status: http.StatusBadRequest, Code: apiErrors.CodeRequestParamError}, acceptLanguage: "zh-CN", want: `{"code":400000,"msg":"请求参数错误"}`},
+	}
+
+	for _, test := range tests {
+
+		resp := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		ctx, _ := gin.CreateTestContext(resp)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set("Accept-Language", test.acceptLanguage)
+
+		Error(ctx, test.input)
+
+		assert.Equal(t, test.want, resp.Body.String(), test.input)
+	}
+
+	resp := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?lang=zh-CN", nil)
+	TokenInvalid(ctx)
+	assert.Equal(t, `{"code":400002,"msg":"请求token无效"}`, resp.Body.String())
+}
@@ -0,0 +1,72 @@
+package response
+
+import (
+	"encoding/json"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+)
+
+// ProtoResponseData is the message of response.proto, it's written by hand to avoid depending on protoc,
+// the struct tags are the same as generated code, so it's encoded by proto.Marshal.
+type ProtoResponseData struct {
+	Code int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Data *any.Any `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Msg  string   `protobuf:"bytes,3,opt,name=msg,proto3" json:"msg,omitempty"`
+}
+
+func (m *ProtoResponseData) Reset()         { *m = ProtoResponseData{} }
+func (m *ProtoResponseData) String() string { return proto.CompactTextString(m) }
+func (*ProtoResponseData) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*ProtoResponseData)(nil), "response.ResponseData")
+}
+
+// ToProto converts ResponseData to protobuf message, the data which is not protobuf message is converted by json.
+func (data ResponseData) ToProto() (*ProtoResponseData, error) {
+
+	message := &ProtoResponseData{
+		Code: int32(data.Code),
+		Msg:  data.Message,
+	}
+
+	if data.Data == nil {
+		return message, nil
+	}
+
+	dataMessage, ok := data.Data.(proto.Message)
+	if !ok {
+		value, err := toValue(data.Data)
+		if err != nil {
+			return nil, err
+		}
+		dataMessage = value
+	}
+
+	dataAny, err := ptypes.MarshalAny(dataMessage)
+	if err != nil {
+		return nil, err
+	}
+	message.Data = dataAny
+
+	return message, nil
+}
+
+func toValue(data interface{}) (*structpb.Value, error) {
+
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	value := &structpb.Value{}
+	if err = jsonpb.UnmarshalString(string(jsonBytes), value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
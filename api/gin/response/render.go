@@ -0,0 +1,77 @@
+package response
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/codec"
+)
+
+const xmlContentType = "application/xml; charset=utf-8"
+
+// Render responds body in the format negotiated by Accept header, see codec.Negotiate.
+// The ResponseData is encoded as ProtoResponseData in protobuf.
+func Render(c *gin.Context, httpStatus int, body interface{}) {
+
+	switch codec.Negotiate(c) {
+	case codec.FormatXML:
+		renderXML(c, httpStatus, body)
+	case codec.FormatMsgPack:
+		c.Render(httpStatus, render.MsgPack{Data: body})
+	case codec.FormatProtobuf:
+		renderProtobuf(c, httpStatus, body)
+	default:
+		c.JSON(httpStatus, body)
+	}
+}
+
+// renderXML marshals body before writing, because gin panics if the render failed,
+// e.g. the struct has map fields which are not supported by encoding/xml.
+func renderXML(c *gin.Context, httpStatus int, body interface{}) {
+
+	content, err := xml.Marshal(body)
+	if err != nil {
+		logrus.WithError(err).Error("convert response to xml failed")
+		c.XML(http.StatusInternalServerError, ResponseData{
+			Code:    errors.CodeServerInternalError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Data(httpStatus, xmlContentType, content)
+}
+
+func renderProtobuf(c *gin.Context, httpStatus int, body interface{}) {
+
+	var data ResponseData
+	switch body := body.(type) {
+	case proto.Message:
+		c.ProtoBuf(httpStatus, body)
+		return
+	case ResponseData:
+		data = body
+	case *ResponseData:
+		data = *body
+	default:
+		data = ResponseData{Data: body}
+	}
+
+	message, err := data.ToProto()
+	if err != nil {
+		logrus.WithError(err).Error("convert response to protobuf failed")
+		c.ProtoBuf(http.StatusInternalServerError, &ProtoResponseData{
+			Code: errors.CodeServerInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.ProtoBuf(httpStatus, message)
+}
@@ -0,0 +1,157 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+
+	apiErrors "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+func newRenderContext(accept string) (*gin.Context, *httptest.ResponseRecorder) {
+
+	resp := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set("Accept", accept)
+
+	return ctx, resp
+}
+
+func TestRender(t *testing.T) {
+
+	type data struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	tests := []struct {
+		accept      string
+		contentType string
+		want        string
+	}{
+		{accept: "", contentType: "application/json; charset=utf-8", want: `{"code":0,"data":{"name":"test"}}`},
+		{accept: "application/json", contentType: "application/json; charset=utf-8", want: `{"code":0,"data":{"name":"test"}}`},
+		{accept: "application/xml", contentType: "application/xml; charset=utf-8", want: `<response><code>0</code><data><name>test</name></data></response>`},
+	}
+
+	for _, test := range tests {
+
+		ctx, resp := newRenderContext(test.accept)
+		Data(ctx, data{Name: "test"})
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, test.contentType, resp.Header().Get("Content-Type"))
+		assert.Equal(t, test.want, resp.Body.String())
+	}
+
+	ctx, resp := newRenderContext("application/x-msgpack")
+	Error(ctx, apiErrors.RequestParamError)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "application/msgpack; charset=utf-8", resp.Header().Get("Content-Type"))
+
+	decoded := map[string]interface{}{}
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	assert.NoError(t, codec.NewDecoderBytes(resp.Body.Bytes(), handle).Decode(&decoded))
+	assert.EqualValues(t, apiErrors.CodeRequestParamError, decoded["code"])
+	assert.Equal(t, "request param error", decoded["msg"])
+}
+
+func TestRender_XML(t *testing.T) {
+
+	tests := []struct {
+		data interface{}
+		want string
+	}{
+		{
+			data: map[string]interface{}{"name": "test", "ids": []uint64{1, 2}, "shop": map[string]int{"id": 3}},
+			want: `<response><code>0</code><data><ids>1</ids><ids>2</ids><name>test</name><shop><id>3</id></shop></data></response>`,
+		},
+		{
+			data: gin.H{"name": "test"},
+			want: `<response><code>0</code><data><name>test</name></data></response>`,
+		},
+		{
+			data: validation.Errors{"name": validation.ErrRequired, "age": validation.ErrNil},
+			want: `<response><code>0</code><data><age>must be blank</age><name>cannot be blank</name></data></response>`,
+		},
+		{
+			data: []gin.H{{"id": 1}, {"id": 2}},
+			want: `<response><code>0</code><data><id>1</id></data><data><id>2</id></data></response>`,
+		},
+	}
+
+	for _, test := range tests {
+
+		ctx, resp := newRenderContext("application/xml")
+		Data(ctx, test.data)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "application/xml; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Equal(t, test.want, resp.Body.String())
+	}
+
+	ctx, resp := newRenderContext("application/xml")
+	Error(ctx, apiErrors.RequestParamError)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, `<response><code>400000</code><msg>request param error</msg></response>`, resp.Body.String())
+
+	// the struct of map field is not supported by encoding/xml, it's responded as server error instead of panic
+	type unsupported struct {
+		Fields map[string]string
+	}
+
+	ctx, resp = newRenderContext("application/xml")
+	assert.NotPanics(t, func() {
+		Data(ctx, unsupported{Fields: map[string]string{"name": "test"}})
+	})
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), `<code>500000</code>`)
+}
+
+func TestRender_Protobuf(t *testing.T) {
+
+	ctx, resp := newRenderContext("application/x-protobuf")
+	Data(ctx, map[string]interface{}{"name": "test"})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-protobuf", resp.Header().Get("Content-Type"))
+
+	message := &ProtoResponseData{}
+	assert.NoError(t, proto.Unmarshal(resp.Body.Bytes(), message))
+
+	value := &structpb.Value{}
+	assert.NoError(t, ptypes.UnmarshalAny(message.Data, value))
+	assert.Equal(t, "test", value.GetStructValue().GetFields()["name"].GetStringValue())
+
+	ctx, resp = newRenderContext("application/x-protobuf")
+	Data(ctx, &wrappers.StringValue{Value: "test"})
+
+	message = &ProtoResponseData{}
+	assert.NoError(t, proto.Unmarshal(resp.Body.Bytes(), message))
+
+	stringValue := &wrappers.StringValue{}
+	assert.NoError(t, ptypes.UnmarshalAny(message.Data, stringValue))
+	assert.Equal(t, "test", stringValue.Value)
+
+	ctx, resp = newRenderContext("application/x-protobuf")
+	Error(ctx, apiErrors.RequestParamError)
+
+	message = &ProtoResponseData{}
+	assert.NoError(t, proto.Unmarshal(resp.Body.Bytes(), message))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.EqualValues(t, apiErrors.CodeRequestParamError, message.Code)
+	assert.Equal(t, "request param error", message.Msg)
+	assert.Nil(t, message.Data)
+}
@@ -0,0 +1,204 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+const (
+	// LastEventIdHeader is sent by browser when reconnecting, the stream can resume from the event
+	LastEventIdHeader = "Last-Event-ID"
+
+	defaultHeartbeatInterval = 15 * time.Second
+)
+
+// ErrClientDisconnected is returned when the client closed the connection during streaming
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// Event is a server-sent event, the Data is written as is if it's a string or number, otherwise it's encoded to json.
+type Event struct {
+	Id    string // generated by sequence if empty
+	Event string
+	Retry time.Duration
+	Data  interface{}
+}
+
+type EventStreamOption func(stream *EventStream)
+
+// EventStreamHeartbeat sets the interval of heartbeat comment, which keeps the connection alive through proxies,
+// heartbeat is disabled if interval <= 0.
+func EventStreamHeartbeat(interval time.Duration) EventStreamOption {
+	return func(stream *EventStream) {
+		stream.heartbeat = interval
+	}
+}
+
+// EventStreamRetry sends the reconnection time hint to client when stream opened.
+func EventStreamRetry(retry time.Duration) EventStreamOption {
+	return func(stream *EventStream) {
+		stream.retry = retry
+	}
+}
+
+// EventStream writes server-sent events, it's safe to send events from multiple goroutines.
+type EventStream struct {
+	c         *gin.Context
+	requestId string
+	heartbeat time.Duration
+	retry     time.Duration
+	sequence  uint64
+	lock      sync.Mutex
+}
+
+// SSE opens an event stream on the response, the request id is responded in header and logged with the stream.
+func SSE(c *gin.Context, options ...EventStreamOption) (*EventStream, error) {
+
+	stream := &EventStream{
+		c:         c,
+		requestId: setStreamHeaders(c),
+		heartbeat: defaultHeartbeatInterval,
+	}
+
+	for _, option := range options {
+		option(stream)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", sse.ContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	c.Status(http.StatusOK)
+
+	if lastEventId := stream.LastEventId(); lastEventId != "" {
+		if sequence, err := strconv.ParseUint(lastEventId, 10, 64); err == nil {
+			stream.sequence = sequence
+		}
+	}
+
+	if stream.retry <= 0 {
+		c.Writer.WriteHeaderNow()
+		c.Writer.Flush()
+		return stream, nil
+	}
+
+	if err := stream.write(func(w io.Writer) error {
+		_, err := io.WriteString(w, "retry:"+strconv.FormatInt(int64(stream.retry/time.Millisecond), 10)+"\n\n")
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// LastEventId returns the id of last event received by client before reconnecting.
+func (stream *EventStream) LastEventId() string {
+	return stream.c.GetHeader(LastEventIdHeader)
+}
+
+func (stream *EventStream) GetRequestId() string {
+	return stream.requestId
+}
+
+// Done is closed when the client disconnected.
+func (stream *EventStream) Done() <-chan struct{} {
+	return stream.c.Request.Context().Done()
+}
+
+// Send writes an event, the id is generated by sequence if it's empty.
+func (stream *EventStream) Send(event Event) error {
+
+	return stream.write(func(w io.Writer) error {
+
+		if event.Id == "" {
+			stream.sequence++
+			event.Id = strconv.FormatUint(stream.sequence, 10)
+		}
+
+		return sse.Encode(w, sse.Event{
+			Id:    event.Id,
+			Event: event.Event,
+			Retry: uint(event.Retry / time.Millisecond),
+			Data:  event.Data,
+		})
+	})
+}
+
+// Heartbeat writes a comment line which is ignored by client.
+func (stream *EventStream) Heartbeat() error {
+
+	return stream.write(func(w io.Writer) error {
+		_, err := io.WriteString(w, ":heartbeat\n\n")
+		return err
+	})
+}
+
+// Stream sends events until the channel is closed or the client disconnected, heartbeats are sent when idle.
+func (stream *EventStream) Stream(events <-chan Event) error {
+
+	var heartbeat <-chan time.Time
+	if stream.heartbeat > 0 {
+		ticker := time.NewTicker(stream.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-stream.Done():
+			logrus.WithField("reqId", stream.requestId).Debug("event stream client disconnected")
+			return ErrClientDisconnected
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-heartbeat:
+			if err := stream.Heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (stream *EventStream) write(write func(w io.Writer) error) error {
+
+	select {
+	case <-stream.Done():
+		return ErrClientDisconnected
+	default:
+	}
+
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+
+	if err := write(stream.c.Writer); err != nil {
+		logrus.WithError(err).WithField("reqId", stream.requestId).Debug("write event stream failed")
+		return ErrClientDisconnected
+	}
+
+	stream.c.Writer.Flush()
+	return nil
+}
+
+// setStreamHeaders responds the request id, which is used to trace the stream, because the body is not logged.
+func setStreamHeaders(c *gin.Context) string {
+
+	requestId := requestid.SetRequestIdIfNotExist(c)
+	c.Header(requestid.Header, requestId)
+
+	return requestId
+}
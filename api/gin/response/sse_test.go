@@ -0,0 +1,70 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+func TestSSE(t *testing.T) {
+
+	resp := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(resp)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set(requestid.Header, "request-id")
+	ctx.Request.Header.Set(LastEventIdHeader, "5")
+
+	stream, err := SSE(ctx, EventStreamRetry(3*time.Second), EventStreamHeartbeat(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "5", stream.LastEventId())
+	assert.Equal(t, "request-id", stream.GetRequestId())
+
+	events := make(chan Event)
+	go func() {
+		events <- Event{Event: "progress", Data: map[string]int{"percent": 50}}
+		time.Sleep(10 * time.Millisecond)
+		events <- Event{Id: "done", Event: "finish", Data: "ok"}
+		close(events)
+	}()
+
+	assert.NoError(t, stream.Stream(events))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "request-id", resp.Header().Get(requestid.Header))
+
+	body := resp.Body.String()
+	assert.Contains(t, body, "retry:3000\n\n")
+	assert.Contains(t, body, "id:6\nevent:progress\ndata:{\"percent\":50}\n\n")
+	assert.Contains(t, body, ":heartbeat\n\n")
+	assert.Contains(t, body, "id:done\nevent:finish\ndata:ok\n\n")
+}
+
+func TestSSE_Disconnected(t *testing.T) {
+
+	resp := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(resp)
+
+	requestContext, cancel := context.WithCancel(context.Background())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(requestContext)
+
+	stream, err := SSE(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Header().Get(requestid.Header))
+
+	cancel()
+
+	assert.Equal(t, ErrClientDisconnected, stream.Stream(make(chan Event)))
+	assert.Equal(t, ErrClientDisconnected, stream.Send(Event{Data: "lost"}))
+	assert.NotContains(t, resp.Body.String(), "lost")
+}
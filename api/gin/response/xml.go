@@ -0,0 +1,114 @@
+package response
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MarshalXML encodes the response as <response><code/><data/><msg/></response>,
+// the map data (e.g. gin.H and validation.Errors) is encoded as the children of <data> by keys,
+// which are not supported by encoding/xml.
+func (data ResponseData) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+
+	start = xml.StartElement{Name: xml.Name{Local: "response"}}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := encoder.EncodeElement(data.Code, xml.StartElement{Name: xml.Name{Local: "code"}}); err != nil {
+		return err
+	}
+
+	if data.Data != nil {
+		if err := encodeXMLValue(encoder, "data", reflect.ValueOf(data.Data)); err != nil {
+			return err
+		}
+	}
+
+	if data.Message != "" {
+		if err := encoder.EncodeElement(data.Message, xml.StartElement{Name: xml.Name{Local: "msg"}}); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+var (
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	marshalerType = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+)
+
+func encodeXMLValue(encoder *xml.Encoder, name string, value reflect.Value) error {
+
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+
+		if value.IsNil() {
+			return nil
+		}
+
+		// the pointers of other types are encoded by encoding/xml, so their marshalers of pointer receiver work
+		if value.Kind() == reflect.Ptr && !isXMLContainer(value.Elem().Kind()) {
+			break
+		}
+
+		value = value.Elem()
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch value.Kind() {
+	case reflect.Map:
+
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("xml: unsupported map key type %s", value.Type().Key())
+		}
+
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			item := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+			if err := encodeXMLValue(encoder, key, item); err != nil {
+				return err
+			}
+		}
+
+		return encoder.EncodeToken(start.End())
+
+	case reflect.Slice, reflect.Array:
+
+		// []byte is encoded as text by encoding/xml
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+
+		for index := 0; index < value.Len(); index++ {
+			if err := encodeXMLValue(encoder, name, value.Index(index)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// the errors in map, e.g. validation.Errors, are encoded as their messages
+	if value.Type().Implements(errorType) && !value.Type().Implements(marshalerType) {
+		return encoder.EncodeElement(value.Interface().(error).Error(), start)
+	}
+
+	return encoder.EncodeElement(value.Interface(), start)
+}
+
+func isXMLContainer(kind reflect.Kind) bool {
+	return kind == reflect.Map || kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,222 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v2"
+)
+
+const DefaultLanguage = "en"
+
+// Catalog is the messages of languages keyed by error code (e.g. "400002") or validator rule (e.g. "validation_required"),
+// the message can be a template with the params of validation error, e.g. "长度必须在 {{.min}} 到 {{.max}} 之间".
+type Catalog struct {
+	lock            sync.RWMutex
+	defaultLanguage string
+	languages       []string
+	messages        map[string]map[string]string
+	matcher         language.Matcher
+}
+
+func NewCatalog(defaultLanguage string) *Catalog {
+
+	catalog := &Catalog{
+		messages: make(map[string]map[string]string),
+	}
+	catalog.SetDefaultLanguage(defaultLanguage)
+
+	return catalog
+}
+
+// SetDefaultLanguage sets the language used when no preference matches
+func (catalog *Catalog) SetDefaultLanguage(lang string) {
+
+	catalog.lock.Lock()
+	defer catalog.lock.Unlock()
+
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	catalog.defaultLanguage = lang
+	catalog.addLanguage(lang)
+}
+
+func (catalog *Catalog) GetDefaultLanguage() string {
+
+	catalog.lock.RLock()
+	defer catalog.lock.RUnlock()
+
+	return catalog.defaultLanguage
+}
+
+// Add merges messages of language, the messages added later override the earlier ones with same keys
+func (catalog *Catalog) Add(lang string, messages map[string]string) error {
+
+	if _, err := language.Parse(lang); err != nil {
+		return fmt.Errorf("invalid language %s: %w", lang, err)
+	}
+
+	catalog.lock.Lock()
+	defer catalog.lock.Unlock()
+
+	catalog.addLanguage(lang)
+
+	for key, message := range messages {
+		catalog.messages[lang][key] = message
+	}
+
+	return nil
+}
+
+func (catalog *Catalog) addLanguage(lang string) {
+
+	if _, exist := catalog.messages[lang]; !exist {
+		catalog.messages[lang] = make(map[string]string)
+	}
+
+	// the default language is the first one, so it's the fallback of matcher
+	languages := []string{catalog.defaultLanguage}
+	tags := []language.Tag{language.Make(catalog.defaultLanguage)}
+	for existLanguage := range catalog.messages {
+		if existLanguage == catalog.defaultLanguage {
+			continue
+		}
+		languages = append(languages, existLanguage)
+		tags = append(tags, language.Make(existLanguage))
+	}
+
+	catalog.languages = languages
+	catalog.matcher = language.NewMatcher(tags)
+}
+
+// Load loads messages file, the language is the file name, e.g. zh-CN.yaml, en.json
+func (catalog *Catalog) Load(path string) error {
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	messages := make(map[string]string)
+	extension := filepath.Ext(path)
+
+	switch strings.ToLower(extension) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &messages)
+	case ".json":
+		err = json.Unmarshal(content, &messages)
+	default:
+		return fmt.Errorf("unsupported messages file %s", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("parse messages file %s error: %w", path, err)
+	}
+
+	return catalog.Add(strings.TrimSuffix(filepath.Base(path), extension), messages)
+}
+
+// LoadPath loads messages file, or all yaml and json files in directory
+func (catalog *Catalog) LoadPath(path string) error {
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return catalog.Load(path)
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		if err = catalog.Load(filepath.Join(path, file.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Match returns the best supported language of preferences, the preference can be a language or Accept-Language header,
+// the earlier preferences take priority.
+func (catalog *Catalog) Match(preferences ...string) string {
+
+	catalog.lock.RLock()
+	defer catalog.lock.RUnlock()
+
+	for _, preference := range preferences {
+
+		if preference == "" {
+			continue
+		}
+
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		_, index, confidence := catalog.matcher.Match(tags...)
+		if confidence == language.No {
+			continue
+		}
+
+		return catalog.languages[index]
+	}
+
+	return catalog.defaultLanguage
+}
+
+// Translate returns the message of key in language, the message is rendered with params if it's a template.
+func (catalog *Catalog) Translate(lang, key string, params map[string]interface{}) (string, bool) {
+
+	catalog.lock.RLock()
+	message, exist := catalog.messages[lang][key]
+	catalog.lock.RUnlock()
+
+	if !exist {
+		return "", false
+	}
+
+	if len(params) == 0 || !strings.Contains(message, "{{") {
+		return message, true
+	}
+
+	return render(message, params), true
+}
+
+func render(message string, params map[string]interface{}) string {
+
+	tmpl, err := template.New("").Parse(message)
+	if err != nil {
+		return message
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = tmpl.Execute(buffer, params); err != nil {
+		return message
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_Match(t *testing.T) {
+
+	catalog := NewCatalog("en")
+	assert.Nil(t, catalog.Add("zh-CN", map[string]string{}))
+	assert.Nil(t, catalog.Add("ja", map[string]string{}))
+
+	tests := []struct {
+		preferences []string
+		want        string
+	}{
+		{preferences: []string{"", "zh-CN,zh;q=0.9,en;q=0.8"}, want: "zh-CN"},
+		{preferences: []string{"zh"}, want: "zh-CN"},
+		{preferences: []string{"en", "zh-CN"}, want: "en"},
+		{preferences: []string{"fr-FR,fr;q=0.9"}, want: "en"},
+		{preferences: []string{"invalid;;q=x", "ja-JP"}, want: "ja"},
+		{preferences: nil, want: "en"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, catalog.Match(test.preferences...), test.preferences)
+	}
+}
+
+func TestCatalog_Translate(t *testing.T) {
+
+	catalog := NewCatalog("en")
+	assert.Nil(t, catalog.Add("zh-CN", map[string]string{
+		"400000":                         "请求参数错误",
+		"validation_length_out_of_range": "长度必须在 {{.min}} 到 {{.max}} 之间",
+	}))
+
+	message, ok := catalog.Translate("zh-CN", "400000", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "请求参数错误", message)
+
+	message, ok = catalog.Translate("zh-CN", "validation_length_out_of_range", map[string]interface{}{"min": 1, "max": 10})
+	assert.True(t, ok)
+	assert.Equal(t, "长度必须在 1 到 10 之间", message)
+
+	_, ok = catalog.Translate("en", "400000", nil)
+	assert.False(t, ok)
+
+	assert.NotNil(t, catalog.Add("???", nil))
+}
+
+func TestCatalog_LoadPath(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"zh-CN.yaml": "\"400002\": token 无效\nvalidation_required: 必须填写\n",
+		"en.json":    `{"400002": "invalid token"}`,
+		"README.md":  "not messages",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog := NewCatalog("en")
+	assert.Nil(t, catalog.LoadPath(dir))
+
+	message, _ := catalog.Translate("zh-CN", "400002", nil)
+	assert.Equal(t, "token 无效", message)
+	message, _ = catalog.Translate("zh-CN", "validation_required", nil)
+	assert.Equal(t, "必须填写", message)
+	message, _ = catalog.Translate("en", "400002", nil)
+	assert.Equal(t, "invalid token", message)
+
+	assert.NotNil(t, catalog.LoadPath(filepath.Join(dir, "README.md")))
+	assert.NotNil(t, catalog.LoadPath(filepath.Join(dir, "not-exist")))
+}
@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	DefaultQueryParameter = "lang"
+	MetadataKey           = "accept-language"
+	contextKey            = "language"
+)
+
+var (
+	defaultCatalog = newDefaultCatalog()
+	queryParameter = DefaultQueryParameter
+)
+
+func newDefaultCatalog() *Catalog {
+
+	catalog := NewCatalog(DefaultLanguage)
+	for lang, messages := range builtinMessages {
+		_ = catalog.Add(lang, messages)
+	}
+
+	return catalog
+}
+
+// GetCatalog returns the catalog used by response, validator and rpc interceptors
+func GetCatalog() *Catalog {
+	return defaultCatalog
+}
+
+func SetDefaultLanguage(lang string) {
+	defaultCatalog.SetDefaultLanguage(lang)
+}
+
+// SetQueryParameter sets the name of query parameter which overrides Accept-Language, e.g. ?lang=en
+func SetQueryParameter(name string) {
+	if name != "" {
+		queryParameter = name
+	}
+}
+
+func AddMessages(lang string, messages map[string]string) error {
+	return defaultCatalog.Add(lang, messages)
+}
+
+// LoadPath loads messages file or directory, see Catalog.Load
+func LoadPath(path string) error {
+	return defaultCatalog.LoadPath(path)
+}
+
+func Translate(lang, key string, params map[string]interface{}) (string, bool) {
+	return defaultCatalog.Translate(lang, key, params)
+}
+
+// TranslateCode translates the message of error code, the message is translated only if it's empty
+// or the default message of registered code, so the specific messages given by caller are kept.
+func TranslateCode(lang string, code int, message string) string {
+
+	if !isDefaultMessage(code, message) {
+		return message
+	}
+
+	if translated, ok := Translate(lang, strconv.Itoa(code), nil); ok {
+		return translated
+	}
+
+	return message
+}
+
+// GetLanguage negotiates language from query parameter and Accept-Language header, the result is cached in context.
+func GetLanguage(c *gin.Context) string {
+
+	if c == nil {
+		return defaultCatalog.GetDefaultLanguage()
+	}
+
+	if lang := c.GetString(contextKey); lang != "" {
+		return lang
+	}
+
+	if c.Request == nil {
+		return defaultCatalog.GetDefaultLanguage()
+	}
+
+	lang := defaultCatalog.Match(c.Query(queryParameter), c.GetHeader("Accept-Language"))
+	c.Set(contextKey, lang)
+
+	return lang
+}
+
+// GetLanguageFromRPCContext negotiates language from accept-language metadata
+func GetLanguageFromRPCContext(ctx context.Context) string {
+
+	if ctx == nil {
+		return defaultCatalog.GetDefaultLanguage()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return defaultCatalog.Match(strings.Join(md.Get(MetadataKey), ","))
+}
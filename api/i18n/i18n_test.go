@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+func TestGetLanguage(t *testing.T) {
+
+	tests := []struct {
+		url            string
+		acceptLanguage string
+		want           string
+	}{
+		{url: "/", acceptLanguage: "zh-CN,zh;q=0.9", want: "zh-CN"},
+		{url: "/?lang=en", acceptLanguage: "zh-CN,zh;q=0.9", want: "en"},
+		{url: "/", acceptLanguage: "", want: DefaultLanguage},
+	}
+
+	for _, test := range tests {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, test.url, nil)
+		c.Request.Header.Set("Accept-Language", test.acceptLanguage)
+
+		assert.Equal(t, test.want, GetLanguage(c), test)
+	}
+
+	assert.Equal(t, DefaultLanguage, GetLanguage(nil))
+}
+
+func TestGetLanguageFromRPCContext(t *testing.T) {
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "zh-CN"))
+	assert.Equal(t, "zh-CN", GetLanguageFromRPCContext(ctx))
+	assert.Equal(t, DefaultLanguage, GetLanguageFromRPCContext(context.Background()))
+}
+
+func TestLocalizeError(t *testing.T) {
+
+	tests := []struct {
+		input error
+		want  string
+	}{
+		{input: errors.RequestTokenInvalid, want: "请求token无效"},
+		{input: fmt.Errorf("wrapped: %w", errors.RequestParamError), want: "请求参数错误"},
+		{input: errors.RequestParamError.WithMessage("name is required"), want: "name is required"},
+		{input: validation.ErrRequired, want: "不能为空"},
+		{input: validation.ErrLengthOutOfRange.SetParams(map[string]interface{}{"min": 2, "max": 8}), want: "长度必须在 2 到 8 之间"},
+		{input: validation.NewError("400000", "the name is too long"), want: "the name is too long"},
+		{input: validation.NewError("400000", "request param error"), want: "请求参数错误"},
+		{input: validation.Errors{"name": validation.ErrRequired}, want: "name: 不能为空."},
+		{input: stderrors.New("plain"), want: "plain"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, LocalizeError("zh-CN", test.input).Error(), test.input)
+	}
+
+	assert.Equal(t, "request 'token' is invalid", LocalizeError("en", errors.RequestTokenInvalid).Error())
+	assert.Nil(t, LocalizeError("zh-CN", nil))
+}
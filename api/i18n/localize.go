@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+// LocalizeError returns the error with message in language, the errors.Error and validation errors are supported,
+// others are returned as is.
+func LocalizeError(lang string, err error) error {
+
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case validation.Error:
+		return localizeValidationError(lang, e)
+	case validation.Errors:
+		localized := make(validation.Errors, len(e))
+		for field, fieldError := range e {
+			localized[field] = LocalizeError(lang, fieldError)
+		}
+		return localized
+	}
+
+	if e, ok := errors.FromError(err); ok {
+		return e.WithMessage(TranslateCode(lang, e.Code(), e.Message()))
+	}
+
+	return err
+}
+
+func localizeValidationError(lang string, err validation.Error) error {
+
+	// the validation error with business code is translated as error code
+	if code, convertError := strconv.Atoi(err.Code()); convertError == nil {
+		return err.SetMessage(TranslateCode(lang, code, err.Message()))
+	}
+
+	translated, ok := Translate(lang, err.Code(), err.Params())
+	if !ok {
+		return err
+	}
+
+	// the params are rendered into message already
+	return err.SetMessage(translated).SetParams(nil)
+}
+
+func isDefaultMessage(code int, message string) bool {
+
+	if message == "" {
+		return true
+	}
+
+	registered, ok := errors.Lookup(uint32(code))
+
+	return ok && registered.Message() == message
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"strconv"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+// builtinMessages translates the codes of api/errors and the rules of ozzo-validation,
+// the english messages are the defaults of them, so they are not listed.
+var builtinMessages = map[string]map[string]string{
+	"zh-CN": {
+		strconv.Itoa(errors.CodeServerInternalError):      "服务内部错误",
+		strconv.Itoa(errors.CodeServiceUnavailable):       "服务不可用",
+		strconv.Itoa(errors.CodeRequestParamError):        "请求参数错误",
+		strconv.Itoa(errors.CodeRequestPathError):         "请求path错误",
+		strconv.Itoa(errors.CodeRequestTokenInvalid):      "请求token无效",
+		strconv.Itoa(errors.CodeRequestTokenExpired):      "请求token过期",
+		strconv.Itoa(errors.CodeRequestJSONDecodeFailed):  "请求的 JSON 解释失败",
+		strconv.Itoa(errors.CodeRequestTokenRevoked):      "请求token已注销",
+		strconv.Itoa(errors.CodeRequestSignatureInvalid):  "请求签名无效",
+		strconv.Itoa(errors.CodeRequestSignatureExpired):  "请求签名过期",
+		strconv.Itoa(errors.CodeRequestNonceReused):       "请求nonce重复",
+		strconv.Itoa(errors.CodeLoggedInElsewhere):        "账号已在其他设备登录",
+		strconv.Itoa(errors.CodeForbidden):                "没有权限",
+		strconv.Itoa(errors.CodeIdempotencyKeyProcessing): "相同幂等键的请求正在处理",
+		strconv.Itoa(errors.CodeIdempotencyKeyReused):     "幂等键已被用于不同的请求",
+		strconv.Itoa(errors.CodeRequestEntityTooLarge):    "请求体过大",
+
+		"validation_required":                        "不能为空",
+		"validation_nil_or_not_empty_required":       "不能为空",
+		"validation_not_nil_required":                "必须填写",
+		"validation_empty":                           "必须为空",
+		"validation_nil":                             "必须为空",
+		"validation_length_empty_required":           "必须为空",
+		"validation_length_invalid":                  "长度必须为 {{.min}}",
+		"validation_length_out_of_range":             "长度必须在 {{.min}} 到 {{.max}} 之间",
+		"validation_length_too_long":                 "长度不能超过 {{.max}}",
+		"validation_length_too_short":                "长度不能少于 {{.min}}",
+		"validation_min_greater_equal_than_required": "不能小于 {{.threshold}}",
+		"validation_min_greater_than_required":       "必须大于 {{.threshold}}",
+		"validation_max_less_equal_than_required":    "不能大于 {{.threshold}}",
+		"validation_max_less_than_required":          "必须小于 {{.threshold}}",
+		"validation_in_invalid":                      "必须是有效的值",
+		"validation_not_in_invalid":                  "不能是列表中的值",
+		"validation_match_invalid":                   "格式不正确",
+		"validation_multiple_of_invalid":             "必须是 {{.base}} 的倍数",
+		"validation_date_invalid":                    "必须是有效的日期",
+		"validation_date_out_of_range":               "日期超出范围",
+	},
+}
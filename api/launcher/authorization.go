@@ -0,0 +1,51 @@
+package launcher
+
+import (
+	"os"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+// initAuthorization creates the policy, it's loaded after connections initialized, because it may be loaded from database.
+func (app *Application) initAuthorization() {
+
+	config := app.config.Authorization
+
+	var loader auth.PolicyLoader
+	switch {
+	case config.PolicyFile != "":
+		loader = auth.NewFilePolicyLoader(config.PolicyFile)
+	case config.Database.Connection != "" && config.Database.Table != "":
+		loader = auth.NewDatabasePolicyLoader(config.Database.Connection, config.Database.Table,
+			config.Database.GetRoleColumn(), config.Database.GetPermissionColumn())
+	default:
+		return
+	}
+
+	app.policy = auth.NewPolicy(
+		auth.PolicySource(loader),
+		auth.PolicyReloadInterval(config.ReloadInterval),
+		auth.PolicyLogger(app.logger),
+	)
+}
+
+func (app *Application) startAuthorization() {
+
+	if app.policy == nil {
+		return
+	}
+
+	if err := app.policy.Start(); err != nil {
+		app.logger.WithError(err).Error("load authorization policy error")
+		os.Exit(1)
+		return
+	}
+
+	app.logger.Info("authorization policy loaded")
+}
+
+// GetPolicy returns the role to permissions policy of configuration, it's nil if authorization is not configured.
+func (app *Application) GetPolicy() *auth.Policy {
+
+	return app.policy
+}
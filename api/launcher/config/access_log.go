@@ -0,0 +1,19 @@
+package config
+
+import (
+	"time"
+)
+
+type AccessLogConfig struct {
+	Format              string        `json:"format" yaml:"format"` // json, combined, or empty to use the application log format
+	File                string        `json:"file" yaml:"file"`     // write access log to its own file if not empty
+	SkipPaths           []string      `json:"skipPaths" yaml:"skipPaths"`
+	SlowThreshold       time.Duration `json:"slowThreshold" yaml:"slowThreshold"`
+	VerySlowThreshold   time.Duration `json:"verySlowThreshold" yaml:"verySlowThreshold"`
+	CaptureHeaders      bool          `json:"captureHeaders" yaml:"captureHeaders"` // log request headers, the redacted headers are masked
+	CaptureRequestBody  bool          `json:"captureRequestBody" yaml:"captureRequestBody"`
+	CaptureResponseBody bool          `json:"captureResponseBody" yaml:"captureResponseBody"`
+	MaxBodySize         int           `json:"maxBodySize" yaml:"maxBodySize"`
+	RedactHeaders       []string      `json:"redactHeaders" yaml:"redactHeaders"`
+	RedactFields        []string      `json:"redactFields" yaml:"redactFields"`
+}
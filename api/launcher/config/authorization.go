@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+)
+
+// AuthorizationConfig is the source of role to permissions policy, the file takes precedence over database.
+type AuthorizationConfig struct {
+	PolicyFile     string                      `json:"policyFile" yaml:"policyFile"` // yaml or json file, e.g. admin: ["*"]
+	Database       AuthorizationDatabaseConfig `json:"database" yaml:"database"`
+	ReloadInterval time.Duration               `json:"reloadInterval" yaml:"reloadInterval"` // reload the policy periodically if it's greater than 0
+}
+
+type AuthorizationDatabaseConfig struct {
+	Connection       string `json:"connection" yaml:"connection"` // the key of mysql connection
+	Table            string `json:"table" yaml:"table"`
+	RoleColumn       string `json:"roleColumn" yaml:"roleColumn"`             // default role
+	PermissionColumn string `json:"permissionColumn" yaml:"permissionColumn"` // default permission
+}
+
+func (config AuthorizationDatabaseConfig) GetRoleColumn() string {
+
+	if config.RoleColumn == "" {
+		return "role"
+	}
+
+	return config.RoleColumn
+}
+
+func (config AuthorizationDatabaseConfig) GetPermissionColumn() string {
+
+	if config.PermissionColumn == "" {
+		return "permission"
+	}
+
+	return config.PermissionColumn
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	defaultRetryInitialInterval = 500 * time.Millisecond
+	defaultRetryMaxInterval     = 10 * time.Second
+	defaultRetryTimeout         = time.Minute
+	defaultMonitorInterval      = 10 * time.Second
+)
+
+type ConnectionConfig struct {
+	RetryInitialInterval time.Duration `json:"retryInitialInterval" yaml:"retryInitialInterval"`
+	RetryMaxInterval     time.Duration `json:"retryMaxInterval" yaml:"retryMaxInterval"`
+	RetryTimeout         time.Duration `json:"retryTimeout" yaml:"retryTimeout"` // deadline of connecting required connections at startup
+	MonitorInterval      time.Duration `json:"monitorInterval" yaml:"monitorInterval"`
+}
+
+func (config ConnectionConfig) GetRetryInitialInterval() time.Duration {
+
+	if config.RetryInitialInterval <= 0 {
+		return defaultRetryInitialInterval
+	}
+	return config.RetryInitialInterval
+}
+
+func (config ConnectionConfig) GetRetryMaxInterval() time.Duration {
+
+	if config.RetryMaxInterval <= 0 {
+		return defaultRetryMaxInterval
+	}
+	return config.RetryMaxInterval
+}
+
+func (config ConnectionConfig) GetRetryTimeout() time.Duration {
+
+	if config.RetryTimeout <= 0 {
+		return defaultRetryTimeout
+	}
+	return config.RetryTimeout
+}
+
+func (config ConnectionConfig) GetMonitorInterval() time.Duration {
+
+	if config.MonitorInterval <= 0 {
+		return defaultMonitorInterval
+	}
+	return config.MonitorInterval
+}
@@ -5,11 +5,23 @@ import (
 )
 
 type GinConfig struct {
-	Enable           bool           `json:"enable,omitempty" yaml:"enable,omitempty"`
-	IP               string         `json:"ip" yaml:"ip"`
-	Port             uint16         `json:"port" yaml:"port"`
-	Mode             WebServiceMode `json:"mode" yaml:"mode"`
-	ReadWriteTimeout time.Duration  `json:"readWriteTimeout" yaml:"readWriteTimeout"`
+	Enable           bool            `json:"enable,omitempty" yaml:"enable,omitempty"`
+	IP               string          `json:"ip" yaml:"ip"`
+	Port             uint16          `json:"port" yaml:"port"`
+	Mode             WebServiceMode  `json:"mode" yaml:"mode"`
+	ReadWriteTimeout time.Duration   `json:"readWriteTimeout" yaml:"readWriteTimeout"`
+	HealthCheckPath  string          `json:"healthCheckPath" yaml:"healthCheckPath"` // register connection health check handler if not empty, e.g. /health
+	AccessLog        AccessLogConfig `json:"accessLog" yaml:"accessLog"`
+	ResponseFormat   string          `json:"responseFormat" yaml:"responseFormat"` // default format when request doesn't specify Accept, json, xml, msgpack or protobuf
+}
+
+func (config GinConfig) GetResponseFormat() string {
+
+	if config.ResponseFormat == "" {
+		return "json"
+	}
+
+	return config.ResponseFormat
 }
 
 // Gin Service Mode
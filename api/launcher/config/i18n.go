@@ -0,0 +1,22 @@
+package config
+
+type I18nConfig struct {
+	DefaultLanguage string `json:"defaultLanguage" yaml:"defaultLanguage"` // used when no language of request matches, default en
+	QueryParameter  string `json:"queryParameter" yaml:"queryParameter"`   // overrides Accept-Language, default lang
+	Path            string `json:"path" yaml:"path"`                       // messages file or directory, the language is the file name, e.g. zh-CN.yaml
+}
+
+func (config I18nConfig) GetDefaultLanguage() string {
+
+	return config.DefaultLanguage
+}
+
+func (config I18nConfig) GetQueryParameter() string {
+
+	return config.QueryParameter
+}
+
+func (config I18nConfig) GetPath() string {
+
+	return config.Path
+}
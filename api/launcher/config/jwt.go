@@ -0,0 +1,73 @@
+package config
+
+import (
+	"time"
+)
+
+type JWTConfig struct {
+	Enable       bool           `json:"enable" yaml:"enable"`
+	Algorithm    string         `json:"algorithm" yaml:"algorithm"` // default algorithm of keys, HS256, RS256, ES256 or EdDSA, default HS256
+	Issuer       string         `json:"issuer" yaml:"issuer"`       // default wanxin
+	Audience     []string       `json:"audience" yaml:"audience"`
+	Lifetime     time.Duration  `json:"lifetime" yaml:"lifetime"` // default 24h
+	Leeway       time.Duration  `json:"leeway" yaml:"leeway"`
+	SigningKeyId string         `json:"signingKeyId" yaml:"signingKeyId"` // default the first key has private key
+	Keys         []JWTKeyConfig `json:"keys" yaml:"keys"`
+	JWKSPath     string         `json:"jwksPath" yaml:"jwksPath"` // register public keys handler on web service if not empty, e.g. /.well-known/jwks.json
+
+	Redis                string        `json:"redis" yaml:"redis"`                               // the key of redis connection saves refresh tokens and revocations, token manager is enabled if not empty
+	RefreshTokenLifetime time.Duration `json:"refreshTokenLifetime" yaml:"refreshTokenLifetime"` // default 720h
+}
+
+// JWTKeyConfig is the key file, it's the secret of HS256, or PEM encoded private key or public key of other algorithms,
+// the public key only verifies tokens, e.g. the old key in rotation.
+type JWTKeyConfig struct {
+	Id        string `json:"id" yaml:"id"`
+	Algorithm string `json:"algorithm" yaml:"algorithm"` // default the algorithm of JWTConfig
+	File      string `json:"file" yaml:"file"`
+}
+
+func (config JWTConfig) GetAlgorithm() string {
+
+	if config.Algorithm == "" {
+		return "HS256"
+	}
+
+	return config.Algorithm
+}
+
+func (config JWTConfig) GetIssuer() string {
+
+	if config.Issuer == "" {
+		return "wanxin"
+	}
+
+	return config.Issuer
+}
+
+func (config JWTConfig) GetLifetime() time.Duration {
+
+	if config.Lifetime <= 0 {
+		return 24 * time.Hour
+	}
+
+	return config.Lifetime
+}
+
+func (config JWTConfig) GetKeyAlgorithm(key JWTKeyConfig) string {
+
+	if key.Algorithm == "" {
+		return config.GetAlgorithm()
+	}
+
+	return key.Algorithm
+}
+
+func (config JWTConfig) GetRefreshTokenLifetime() time.Duration {
+
+	if config.RefreshTokenLifetime <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return config.RefreshTokenLifetime
+}
@@ -11,6 +11,7 @@ type MySQLConfig struct {
 	Password string `json:"password" yaml:"password"`
 	Database string `json:"database" yaml:"database"`
 	LogMode  bool   `json:"logMode" yaml:"logMode"`
+	Required bool   `json:"required" yaml:"required"` // required connection must be connected before service start
 }
 
 func (config MySQLConfig) GetHost() string {
@@ -51,3 +52,8 @@ func (config MySQLConfig) GetLogMode() bool {
 
 	return config.LogMode
 }
+
+func (config MySQLConfig) IsRequired() bool {
+
+	return config.Required
+}
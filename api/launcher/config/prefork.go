@@ -0,0 +1,53 @@
+package config
+
+import (
+	"runtime"
+	"time"
+)
+
+const (
+	defaultPreforkRestartDelay = time.Second
+	defaultPreforkReadyDelay   = 3 * time.Second
+	defaultPreforkStopTimeout  = 30 * time.Second
+)
+
+// PreforkConfig forks multiple worker processes share the web and rpc ports by SO_REUSEPORT.
+type PreforkConfig struct {
+	Enable       bool          `json:"enable" yaml:"enable"`
+	Workers      uint16        `json:"workers" yaml:"workers"`           // default is the number of CPU
+	RestartDelay time.Duration `json:"restartDelay" yaml:"restartDelay"` // delay before restart crashed worker
+	ReadyDelay   time.Duration `json:"readyDelay" yaml:"readyDelay"`     // rolling restart waits new worker ready before stop the old one
+	StopTimeout  time.Duration `json:"stopTimeout" yaml:"stopTimeout"`   // kill workers if not exited after stop timeout
+}
+
+func (config PreforkConfig) GetWorkers() uint16 {
+
+	if config.Workers == 0 {
+		return uint16(runtime.NumCPU())
+	}
+	return config.Workers
+}
+
+func (config PreforkConfig) GetRestartDelay() time.Duration {
+
+	if config.RestartDelay <= 0 {
+		return defaultPreforkRestartDelay
+	}
+	return config.RestartDelay
+}
+
+func (config PreforkConfig) GetReadyDelay() time.Duration {
+
+	if config.ReadyDelay <= 0 {
+		return defaultPreforkReadyDelay
+	}
+	return config.ReadyDelay
+}
+
+func (config PreforkConfig) GetStopTimeout() time.Duration {
+
+	if config.StopTimeout <= 0 {
+		return defaultPreforkStopTimeout
+	}
+	return config.StopTimeout
+}
@@ -8,6 +8,7 @@ type RedisConfig struct {
 	Port     uint16 `json:"port" yaml:"port"`
 	Password string `json:"password" yaml:"password"`
 	Database uint8  `json:"database" yaml:"database"`
+	Required bool   `json:"required" yaml:"required"` // required connection must be connected before service start
 }
 
 func (config RedisConfig) GetHost() string {
@@ -35,3 +36,8 @@ func (config RedisConfig) GetDatabase() uint8 {
 
 	return config.Database
 }
+
+func (config RedisConfig) IsRequired() bool {
+
+	return config.Required
+}
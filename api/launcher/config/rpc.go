@@ -4,11 +4,46 @@ import (
 	"time"
 )
 
+const defaultRPCGatewayPathPrefix = "/rpc"
+
 type RPCConfig struct {
-	Enable           bool          `json:"enable,omitempty" yaml:"enable,omitempty"`
-	IP               string        `json:"ip" yaml:"ip"`
-	Port             uint16        `json:"port" yaml:"port"`
-	ReadWriteTimeout time.Duration `json:"readWriteTimeout" yaml:"readWriteTimeout"`
-	TTL              time.Duration `json:"ttl" yaml:"ttl"`
-	Interval         time.Duration `json:"interval" yaml:"interval"`
+	Enable           bool             `json:"enable,omitempty" yaml:"enable,omitempty"`
+	IP               string           `json:"ip" yaml:"ip"`
+	Port             uint16           `json:"port" yaml:"port"`
+	ReadWriteTimeout time.Duration    `json:"readWriteTimeout" yaml:"readWriteTimeout"` // deadline of unary calls which have no deadline set by client
+	TTL              time.Duration    `json:"ttl" yaml:"ttl"`
+	Interval         time.Duration    `json:"interval" yaml:"interval"`
+	Gateway          RPCGatewayConfig `json:"gateway" yaml:"gateway"`
+
+	MaxRecvMsgSize       int                `json:"maxRecvMsgSize" yaml:"maxRecvMsgSize"` // bytes, default 4MB
+	MaxSendMsgSize       int                `json:"maxSendMsgSize" yaml:"maxSendMsgSize"` // bytes, default unlimited
+	MaxConcurrentStreams uint32             `json:"maxConcurrentStreams" yaml:"maxConcurrentStreams"`
+	Keepalive            RPCKeepaliveConfig `json:"keepalive" yaml:"keepalive"`
+	Reflection           bool               `json:"reflection" yaml:"reflection"` // register reflection service for grpcurl, should be enabled in development only
+}
+
+// RPCKeepaliveConfig is the keepalive and connection age of server, zero value means the grpc default.
+type RPCKeepaliveConfig struct {
+	MaxConnectionIdle     time.Duration `json:"maxConnectionIdle" yaml:"maxConnectionIdle"`
+	MaxConnectionAge      time.Duration `json:"maxConnectionAge" yaml:"maxConnectionAge"`           // close the connection after the age, so clients rebalance to new instances
+	MaxConnectionAgeGrace time.Duration `json:"maxConnectionAgeGrace" yaml:"maxConnectionAgeGrace"` // wait for pending calls after max connection age
+	Time                  time.Duration `json:"time" yaml:"time"`                                   // ping client after connection idle for the time
+	Timeout               time.Duration `json:"timeout" yaml:"timeout"`
+	MinTime               time.Duration `json:"minTime" yaml:"minTime"` // minimum ping interval permitted from client, default 5m
+	PermitWithoutStream   bool          `json:"permitWithoutStream" yaml:"permitWithoutStream"`
+}
+
+// RPCGatewayConfig mounts the rpc services on web service as POST {pathPrefix}/{service}/{method}
+type RPCGatewayConfig struct {
+	Enable      bool   `json:"enable" yaml:"enable"`
+	PathPrefix  string `json:"pathPrefix" yaml:"pathPrefix"`
+	MaxBodySize int64  `json:"maxBodySize" yaml:"maxBodySize"` // bytes, default is 4MB
+}
+
+func (config RPCGatewayConfig) GetPathPrefix() string {
+
+	if config.PathPrefix == "" {
+		return defaultRPCGatewayPathPrefix
+	}
+	return config.PathPrefix
 }
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	defaultRPCClientTimeout          = 3 * time.Second
+	defaultRPCClientRetryBackoff     = 100 * time.Millisecond
+	defaultRPCClientConnectTimeout   = 5 * time.Second
+	defaultRPCClientKeepaliveTime    = 5 * time.Minute // the default minimum ping interval permitted by grpc server
+	defaultRPCClientKeepaliveTimeout = 20 * time.Second
+)
+
+type RPCClientConfig struct {
+	Target            string                   `json:"target" yaml:"target"`
+	Timeout           time.Duration            `json:"timeout" yaml:"timeout"`                     // default timeout of every call
+	Retries           uint                     `json:"retries" yaml:"retries"`                     // retry times of idempotent methods when server unavailable
+	RetryBackoff      time.Duration            `json:"retryBackoff" yaml:"retryBackoff"`           // wait before first retry, doubled every retry
+	IdempotentMethods []string                 `json:"idempotentMethods" yaml:"idempotentMethods"` // full method names safe to retry, the others are not retried
+	MethodTimeouts    []RPCMethodTimeoutConfig `json:"methodTimeouts" yaml:"methodTimeouts"`
+	ConnectTimeout    time.Duration            `json:"connectTimeout" yaml:"connectTimeout"`
+	KeepaliveTime     time.Duration            `json:"keepaliveTime" yaml:"keepaliveTime"`
+	KeepaliveTimeout  time.Duration            `json:"keepaliveTimeout" yaml:"keepaliveTimeout"`
+	Required          bool                     `json:"required" yaml:"required"` // required connection must be connected before service start
+}
+
+// RPCMethodTimeoutConfig overrides the default timeout for a method,
+// it's a list rather than a map because the full method name contains dots and upper cases.
+type RPCMethodTimeoutConfig struct {
+	Method  string        `json:"method" yaml:"method"` // full method name, e.g. /protos.ExampleController/Ping
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (config RPCClientConfig) GetTarget() string {
+
+	return config.Target
+}
+
+func (config RPCClientConfig) GetTimeout() time.Duration {
+
+	if config.Timeout <= 0 {
+		return defaultRPCClientTimeout
+	}
+	return config.Timeout
+}
+
+func (config RPCClientConfig) GetRetries() uint {
+
+	return config.Retries
+}
+
+func (config RPCClientConfig) GetRetryBackoff() time.Duration {
+
+	if config.RetryBackoff <= 0 {
+		return defaultRPCClientRetryBackoff
+	}
+	return config.RetryBackoff
+}
+
+func (config RPCClientConfig) GetIdempotentMethods() []string {
+
+	return config.IdempotentMethods
+}
+
+func (config RPCClientConfig) GetMethodTimeouts() map[string]time.Duration {
+
+	timeouts := make(map[string]time.Duration, len(config.MethodTimeouts))
+	for _, methodTimeout := range config.MethodTimeouts {
+		timeouts[methodTimeout.Method] = methodTimeout.Timeout
+	}
+	return timeouts
+}
+
+func (config RPCClientConfig) GetConnectTimeout() time.Duration {
+
+	if config.ConnectTimeout <= 0 {
+		return defaultRPCClientConnectTimeout
+	}
+	return config.ConnectTimeout
+}
+
+func (config RPCClientConfig) GetKeepaliveTime() time.Duration {
+
+	if config.KeepaliveTime <= 0 {
+		return defaultRPCClientKeepaliveTime
+	}
+	return config.KeepaliveTime
+}
+
+func (config RPCClientConfig) GetKeepaliveTimeout() time.Duration {
+
+	if config.KeepaliveTimeout <= 0 {
+		return defaultRPCClientKeepaliveTimeout
+	}
+	return config.KeepaliveTimeout
+}
+
+func (config RPCClientConfig) IsRequired() bool {
+
+	return config.Required
+}
@@ -0,0 +1,34 @@
+package config
+
+const defaultMemoryLimitRatio = 0.8
+
+// RuntimeConfig overrides the values detected from cgroup limits.
+type RuntimeConfig struct {
+	MaxProcs         int     `json:"maxProcs" yaml:"maxProcs"`                 // GOMAXPROCS, detected from cgroup cpu quota if zero
+	GCPercent        int     `json:"gcPercent" yaml:"gcPercent"`               // fixed GOGC, disable gc tuning if not zero, -1 means turn off GC
+	MemoryLimit      uint64  `json:"memoryLimit" yaml:"memoryLimit"`           // bytes, detected from cgroup memory limit if zero
+	MemoryLimitRatio float64 `json:"memoryLimitRatio" yaml:"memoryLimitRatio"` // heap target is memoryLimit * ratio, leaves the rest to stacks and off-heap memory
+}
+
+func (config RuntimeConfig) GetMaxProcs() int {
+
+	return config.MaxProcs
+}
+
+func (config RuntimeConfig) GetGCPercent() int {
+
+	return config.GCPercent
+}
+
+func (config RuntimeConfig) GetMemoryLimit() uint64 {
+
+	return config.MemoryLimit
+}
+
+func (config RuntimeConfig) GetMemoryLimitRatio() float64 {
+
+	if config.MemoryLimitRatio <= 0 || config.MemoryLimitRatio > 1 {
+		return defaultMemoryLimitRatio
+	}
+	return config.MemoryLimitRatio
+}
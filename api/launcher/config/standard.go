@@ -9,12 +9,19 @@ const (
 )
 
 type StandardConfig struct {
-	Web       GinConfig              `json:"web" yaml:"web"`
-	RPC       RPCConfig              `json:"rpc" yaml:"rpc"`
-	MySQL     map[string]MySQLConfig `json:"mysql" yaml:"mysql"`
-	Redis     map[string]RedisConfig `json:"redis" yaml:"redis"`
-	Log       LogConfig              `json:"log" yaml:"log"`
-	ServiceId uint16                 `json:"-" yaml:"-"` // used to distinguish between different services when highly available. no parse from configuration file, because services will use the same configuration file.
+	Web           GinConfig                  `json:"web" yaml:"web"`
+	RPC           RPCConfig                  `json:"rpc" yaml:"rpc"`
+	MySQL         map[string]MySQLConfig     `json:"mysql" yaml:"mysql"`
+	Redis         map[string]RedisConfig     `json:"redis" yaml:"redis"`
+	RPCClients    map[string]RPCClientConfig `json:"rpcClients" yaml:"rpcClients"`
+	Log           LogConfig                  `json:"log" yaml:"log"`
+	Connection    ConnectionConfig           `json:"connection" yaml:"connection"`
+	Prefork       PreforkConfig              `json:"prefork" yaml:"prefork"`
+	Runtime       RuntimeConfig              `json:"runtime" yaml:"runtime"`
+	I18n          I18nConfig                 `json:"i18n" yaml:"i18n"`
+	JWT           JWTConfig                  `json:"jwt" yaml:"jwt"`
+	Authorization AuthorizationConfig        `json:"authorization" yaml:"authorization"`
+	ServiceId     uint16                     `json:"-" yaml:"-"` // used to distinguish between different services when highly available. no parse from configuration file, because services will use the same configuration file.
 }
 
 func (config StandardConfig) String() string {
@@ -0,0 +1,377 @@
+package launcher
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	launcherConfig "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/config"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/client"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/database"
+)
+
+const expvarConnectionsName = "connections"
+
+type ConnectionType string
+
+const (
+	ConnectionTypeMySQL ConnectionType = "mysql"
+	ConnectionTypeRedis ConnectionType = "redis"
+	ConnectionTypeRPC   ConnectionType = "rpc"
+)
+
+type ConnectionState struct {
+	Type          ConnectionType `json:"type"`
+	Key           string         `json:"key"`
+	Required      bool           `json:"required"`
+	Connected     bool           `json:"connected"`
+	LastError     string         `json:"lastError,omitempty"`
+	LastCheckedAt time.Time      `json:"lastCheckedAt"`
+	Failures      uint64         `json:"failures"`   // count of failed checks
+	Reconnects    uint64         `json:"reconnects"` // count of succeed reconnections after failed
+}
+
+type managedConnection struct {
+	state   ConnectionState
+	connect func() error
+	ping    func() error
+}
+
+type connectionMonitor struct {
+	lock        sync.RWMutex
+	connections []*managedConnection
+	config      launcherConfig.ConnectionConfig
+	logger      *logrus.Entry
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+var publishExpvarOnce sync.Once
+
+func newConnectionMonitor(logger *logrus.Entry, config launcherConfig.ConnectionConfig) *connectionMonitor {
+
+	return &connectionMonitor{
+		connections: make([]*managedConnection, 0),
+		config:      config,
+		logger:      logger.WithField("component", "connectionMonitor"),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (monitor *connectionMonitor) add(connectionType ConnectionType, key string, required bool, connect, ping func() error) {
+
+	monitor.connections = append(monitor.connections, &managedConnection{
+		state: ConnectionState{
+			Type:     connectionType,
+			Key:      key,
+			Required: required,
+		},
+		connect: connect,
+		ping:    ping,
+	})
+}
+
+// connectAll connects every connection, the required connections are retried with exponential backoff until deadline.
+func (monitor *connectionMonitor) connectAll() error {
+
+	deadline := time.Now().Add(monitor.config.GetRetryTimeout())
+
+	for _, conn := range monitor.connections {
+
+		logger := monitor.logger.WithField("type", conn.state.Type).WithField("key", conn.state.Key)
+
+		if !conn.state.Required {
+
+			err := conn.connect()
+			monitor.updateState(conn, err, false)
+			if err != nil {
+				logger.WithError(err).Warn("connect optional connection failed, will retry in background")
+			}
+			continue
+		}
+
+		err := monitor.connectWithRetry(conn, deadline, logger)
+		if err != nil {
+			return fmt.Errorf("connect required %s connection %s failed: %w", conn.state.Type, conn.state.Key, err)
+		}
+	}
+
+	return nil
+}
+
+func (monitor *connectionMonitor) connectWithRetry(conn *managedConnection, deadline time.Time, logger *logrus.Entry) error {
+
+	interval := monitor.config.GetRetryInitialInterval()
+
+	for attempt := 1; ; attempt++ {
+
+		err := conn.connect()
+		monitor.updateState(conn, err, false)
+		if err == nil {
+			return nil
+		}
+
+		if !time.Now().Add(interval).Before(deadline) {
+			logger.WithError(err).WithField("attempt", attempt).Error("connect required connection failed, deadline exceeded")
+			return err
+		}
+
+		logger.WithError(err).
+			WithField("attempt", attempt).
+			WithField("retryAfter", interval).
+			Warn("connect required connection failed, retry later")
+
+		time.Sleep(interval)
+		interval = nextRetryInterval(interval, monitor.config.GetRetryMaxInterval())
+	}
+}
+
+func nextRetryInterval(current, maximum time.Duration) time.Duration {
+
+	next := current * 2
+	if next > maximum || next <= 0 {
+		return maximum
+	}
+
+	return next
+}
+
+func (monitor *connectionMonitor) start() {
+
+	publishExpvarOnce.Do(func() {
+		if expvar.Get(expvarConnectionsName) == nil {
+			expvar.Publish(expvarConnectionsName, expvar.Func(func() interface{} {
+				return monitor.states()
+			}))
+		}
+	})
+
+	go monitor.run()
+}
+
+func (monitor *connectionMonitor) run() {
+
+	defer close(monitor.done)
+
+	ticker := time.NewTicker(monitor.config.GetMonitorInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			monitor.check()
+		case <-monitor.stop:
+			return
+		}
+	}
+}
+
+func (monitor *connectionMonitor) check() {
+
+	for _, conn := range monitor.connections {
+
+		err := conn.ping()
+		if err == nil {
+			monitor.updateState(conn, nil, false)
+			continue
+		}
+
+		logger := monitor.logger.WithField("type", conn.state.Type).WithField("key", conn.state.Key)
+		logger.WithError(err).Warn("connection lost, try to reconnect")
+
+		err = conn.connect()
+		monitor.updateState(conn, err, true)
+		if err != nil {
+			logger.WithError(err).Error("reconnect failed")
+			continue
+		}
+
+		logger.Info("reconnected")
+	}
+}
+
+func (monitor *connectionMonitor) updateState(conn *managedConnection, err error, reconnecting bool) {
+
+	monitor.lock.Lock()
+	defer monitor.lock.Unlock()
+
+	wasConnected := conn.state.Connected
+	conn.state.LastCheckedAt = time.Now()
+
+	if err != nil {
+		conn.state.Connected = false
+		conn.state.LastError = err.Error()
+		conn.state.Failures++
+		return
+	}
+
+	conn.state.Connected = true
+	conn.state.LastError = ""
+	if reconnecting && !wasConnected {
+		conn.state.Reconnects++
+	}
+}
+
+func (monitor *connectionMonitor) close() {
+
+	close(monitor.stop)
+	<-monitor.done
+}
+
+func (monitor *connectionMonitor) states() []ConnectionState {
+
+	monitor.lock.RLock()
+	defer monitor.lock.RUnlock()
+
+	states := make([]ConnectionState, 0, len(monitor.connections))
+	for _, conn := range monitor.connections {
+		states = append(states, conn.state)
+	}
+
+	return states
+}
+
+func (monitor *connectionMonitor) String() string {
+
+	jsonBytes, err := json.Marshal(monitor.states())
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonBytes)
+}
+
+func (app *Application) initConnections() {
+
+	app.monitor = newConnectionMonitor(app.logger, app.config.Connection)
+
+	app.logger.Debug("start to connect mysql clients")
+	database.SetLogger(app.logger.Logger)
+
+	for key, mysqlConfig := range app.config.MySQL {
+
+		key := key
+		config := database.NewMySQLConfig(
+			database.MySQLHost(mysqlConfig.GetHost()),
+			database.MySQLPort(mysqlConfig.GetPort()),
+			database.MySQLUsername(mysqlConfig.GetUsername()),
+			database.MySQLPassword(mysqlConfig.GetPassword()),
+			database.MySQLDatabase(mysqlConfig.GetDatabase()),
+			database.MySQLLogMode(mysqlConfig.GetLogMode()),
+		)
+
+		app.monitor.add(ConnectionTypeMySQL, key, mysqlConfig.IsRequired(),
+			func() error { return database.Connect(key, config) },
+			func() error { return database.Ping(key) },
+		)
+	}
+
+	app.logger.Debug("start to connect redis clients")
+	cache.SetLogger(app.logger.Logger)
+
+	for key, redisConfig := range app.config.Redis {
+
+		key := key
+		config := cache.NewRedisConfig(
+			cache.RedisHost(redisConfig.GetHost()),
+			cache.RedisPort(redisConfig.GetPort()),
+			cache.RedisPassword(redisConfig.GetPassword()),
+			cache.RedisDatabase(redisConfig.GetDatabase()),
+		)
+
+		app.monitor.add(ConnectionTypeRedis, key, redisConfig.IsRequired(),
+			func() error { return cache.Connect(key, config) },
+			func() error { return cache.Ping(key) },
+		)
+	}
+
+	app.logger.Debug("start to connect rpc clients")
+	client.SetLogger(app.logger.Logger)
+
+	for key, clientConfig := range app.config.RPCClients {
+
+		key := key
+		options := []client.Option{
+			client.Target(clientConfig.GetTarget()),
+			client.Timeout(clientConfig.GetTimeout()),
+			client.Retry(clientConfig.GetRetries(), clientConfig.GetRetryBackoff()),
+			client.IdempotentMethods(clientConfig.GetIdempotentMethods()...),
+			client.ConnectTimeout(clientConfig.GetConnectTimeout()),
+			client.Keepalive(clientConfig.GetKeepaliveTime(), clientConfig.GetKeepaliveTimeout()),
+		}
+		for method, timeout := range clientConfig.GetMethodTimeouts() {
+			options = append(options, client.MethodTimeout(method, timeout))
+		}
+		config := client.NewConfig(options...)
+
+		app.monitor.add(ConnectionTypeRPC, key, clientConfig.IsRequired(),
+			func() error { return client.Connect(key, config) },
+			func() error { return client.Ping(key) },
+		)
+	}
+
+	if err := app.monitor.connectAll(); err != nil {
+		app.logger.WithError(err).Error("connect required connection error")
+		app.releaseConnections()
+		os.Exit(1)
+		return
+	}
+
+	app.logger.WithField("connections", app.monitor).Info("connections connected")
+}
+
+// GetConnectionStates returns the states of mysql, redis and rpc client connections, used for health check and metrics.
+func (app *Application) GetConnectionStates() []ConnectionState {
+
+	if app.monitor == nil {
+		return []ConnectionState{}
+	}
+
+	return app.monitor.states()
+}
+
+// CheckConnections returns error if any required connection is disconnected.
+func (app *Application) CheckConnections() error {
+
+	if app.monitor == nil {
+		return fmt.Errorf("connections not initialized")
+	}
+
+	for _, state := range app.monitor.states() {
+		if state.Required && !state.Connected {
+			return fmt.Errorf("required %s connection %s disconnected: %s", state.Type, state.Key, state.LastError)
+		}
+	}
+
+	return nil
+}
+
+// HealthCheckHandler responds 200 if all required connections are connected, otherwise 503.
+func (app *Application) HealthCheckHandler(c *gin.Context) {
+
+	states := app.GetConnectionStates()
+
+	if err := app.CheckConnections(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, response.ResponseData{
+			Code:    errors.CodeServiceUnavailable,
+			Data:    states,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ResponseData{
+		Data: states,
+	})
+}
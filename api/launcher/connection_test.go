@@ -0,0 +1,28 @@
+package launcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextRetryInterval(t *testing.T) {
+
+	tests := []struct {
+		current time.Duration
+		maximum time.Duration
+		want    time.Duration
+	}{
+		{current: time.Second, maximum: 30 * time.Second, want: 2 * time.Second},
+		{current: 10 * time.Second, maximum: 30 * time.Second, want: 20 * time.Second},
+		{current: 20 * time.Second, maximum: 30 * time.Second, want: 30 * time.Second},
+		{current: 30 * time.Second, maximum: 30 * time.Second, want: 30 * time.Second},
+		{current: 0, maximum: 30 * time.Second, want: 30 * time.Second},
+		{current: time.Duration(1) << 62, maximum: time.Minute, want: time.Minute}, // overflow
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, nextRetryInterval(test.current, test.maximum), test)
+	}
+}
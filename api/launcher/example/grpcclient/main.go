@@ -6,32 +6,43 @@ import (
 	"os"
 	"time"
 
-	"google.golang.org/grpc"
-
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/example/protos"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/client"
 )
 
 const (
+	clientKey      = "example"
 	address        = "localhost:8088"
 	defaultMessage = "world"
 )
 
+// The services started by launcher declare clients in the rpcClients section of configuration,
+// and get them by client.Get(key) directly.
 func main() {
 	// Set up a connection to the server.
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock())
+	err := client.Connect(clientKey, client.NewConfig(
+		client.Target(address),
+		client.Timeout(time.Second),
+		client.Retry(2, 100*time.Millisecond),
+	))
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
-	defer conn.Close()
-	c := protos.NewExampleControllerClient(conn)
+	defer client.Disconnect(clientKey)
+
+	conn, err := client.Get(clientKey)
+	if err != nil {
+		log.Fatalf("get connection failed: %v", err)
+	}
+	c := protos.NewExampleControllerClient(conn.ClientConn)
 
 	// Contact the server and print out its response.
 	message := defaultMessage
 	if len(os.Args) > 1 {
 		message = os.Args[1]
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+	ctx := requestid.NewRPCContext(context.Background(), requestid.GenerateRequestId())
 	r, err := c.Ping(ctx, &protos.PingRequest{Message: message})
 	if err != nil {
 		log.Fatalf("could not greet: %v", err)
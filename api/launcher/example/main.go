@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
 
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher"
@@ -48,11 +49,28 @@ func main() {
 				}),
 			),
 		),
+		launcher.AddApplicationTasks(
+			launcher.NewTask("echo", echo,
+				launcher.TaskShortDescription("print the arguments"),
+				launcher.TaskFlags(func(flags *pflag.FlagSet) {
+					flags.StringVar(&echoPrefix, "prefix", "echo:", "prefix of the output")
+				}),
+			),
+		),
 	)
 
 	app.Launch()
 }
 
+var echoPrefix string
+
+// usage: builds/debug/service --config=./config/example.yaml task echo --prefix=">" hello world
+func echo(ctx context.Context, app *launcher.Application, args []string) error {
+
+	logrus.WithField("serviceId", app.GetServiceId()).Info(echoPrefix, args)
+	return nil
+}
+
 func pong(c *gin.Context) {
 
 	response.Response(c, "PONG")
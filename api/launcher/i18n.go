@@ -0,0 +1,27 @@
+package launcher
+
+import (
+	"os"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/i18n"
+)
+
+func (app *Application) initI18n() {
+
+	config := app.config.I18n
+
+	i18n.SetDefaultLanguage(config.GetDefaultLanguage())
+	i18n.SetQueryParameter(config.GetQueryParameter())
+
+	if config.GetPath() == "" {
+		return
+	}
+
+	if err := i18n.LoadPath(config.GetPath()); err != nil {
+		app.logger.WithError(err).WithField("path", config.GetPath()).Error("load i18n messages error")
+		os.Exit(1)
+		return
+	}
+
+	app.logger.WithField("path", config.GetPath()).Info("i18n messages loaded")
+}
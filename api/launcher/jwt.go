@@ -0,0 +1,89 @@
+package launcher
+
+import (
+	"os"
+
+	ginAuth "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/auth"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+func (app *Application) initJWT() {
+
+	config := app.config.JWT
+	if !config.Enable {
+		return
+	}
+
+	keys := make([]*auth.Key, 0, len(config.Keys))
+	for _, keyConfig := range config.Keys {
+
+		key, err := auth.LoadKeyFile(keyConfig.Id, config.GetKeyAlgorithm(keyConfig), keyConfig.File)
+		if err != nil {
+			app.logger.WithError(err).WithField("keyId", keyConfig.Id).Error("load jwt key error")
+			os.Exit(1)
+			return
+		}
+
+		keys = append(keys, key)
+	}
+
+	options := []auth.JWTOption{
+		auth.JWTIssuer(config.GetIssuer()),
+		auth.JWTAudience(config.Audience...),
+		auth.JWTLifetime(config.GetLifetime()),
+		auth.JWTLeeway(config.Leeway),
+		auth.JWTKeys(keys...),
+	}
+
+	if config.SigningKeyId != "" {
+		options = append(options, auth.JWTSigningKey(config.SigningKeyId))
+	}
+
+	j, err := auth.NewJWT(options...)
+	if err != nil {
+		app.logger.WithError(err).Error("init jwt error")
+		os.Exit(1)
+		return
+	}
+
+	app.jwt = j
+	app.logger.WithField("keys", len(keys)).Info("jwt initialized")
+
+	if config.Redis == "" {
+		return
+	}
+
+	app.sessionManager = auth.NewSessionManager(auth.NewRedisSessionStore(config.Redis))
+	app.tokenManager = auth.NewTokenManager(j, auth.NewRedisTokenStore(config.Redis),
+		auth.TokenManagerRefreshLifetime(config.GetRefreshTokenLifetime()),
+		auth.TokenManagerSessions(app.sessionManager))
+	app.logger.WithField("redis", config.Redis).Info("token manager initialized")
+}
+
+func (app *Application) initJWKSHandler() {
+
+	if app.jwt == nil || app.config.JWT.JWKSPath == "" || app.GetWebService() == nil {
+		return
+	}
+
+	app.logger.WithField("path", app.config.JWT.JWKSPath).Info("register jwks handler")
+	app.GetWebService().GetEngine().GET(app.config.JWT.JWKSPath, ginAuth.JWKSHandler(app.jwt))
+}
+
+// GetJWT returns the jwt issuer and verifier of configuration, it's nil if jwt is not enabled.
+func (app *Application) GetJWT() *auth.JWT {
+
+	return app.jwt
+}
+
+// GetTokenManager returns the manager of refresh tokens and revocations, it's nil if jwt.redis is not configured.
+func (app *Application) GetTokenManager() *auth.TokenManager {
+
+	return app.tokenManager
+}
+
+// GetSessionManager returns the manager of single session per device class, it's nil if jwt.redis is not configured.
+func (app *Application) GetSessionManager() *auth.SessionManager {
+
+	return app.sessionManager
+}
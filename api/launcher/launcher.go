@@ -13,10 +13,18 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/codec"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/log/request"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/recovery"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/cmd"
 	launcherConfig "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/config"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/service"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/client"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/gateway"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/database"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/log"
@@ -31,11 +39,22 @@ type ApplicationDescription struct {
 type ApplicationOption func(app *Application)
 
 type Application struct {
-	logger      *logrus.Entry
-	description *ApplicationDescription
-	services    []service.Interface
-	config      *launcherConfig.StandardConfig
-	events      *Events
+	logger         *logrus.Entry
+	description    *ApplicationDescription
+	services       []service.Interface
+	config         *launcherConfig.StandardConfig
+	events         *Events
+	tasks          []*Task
+	monitor        *connectionMonitor
+	reporters      []recovery.Reporter
+	jwt            *auth.JWT
+	tokenManager   *auth.TokenManager
+	sessionManager *auth.SessionManager
+	policy         *auth.Policy
+	accessLogFile  *os.File // closed after web service stopped
+
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
 }
 
 func NewApplication(options ...ApplicationOption) *Application {
@@ -45,6 +64,7 @@ func NewApplication(options ...ApplicationOption) *Application {
 		services:    make([]service.Interface, 0),
 		config:      &launcherConfig.StandardConfig{},
 		events:      &Events{},
+		tasks:       make([]*Task, 0),
 	}
 
 	for _, option := range options {
@@ -68,17 +88,13 @@ func (app *Application) Launch() {
 
 	cmd.GetRootCommand().Run = func(cmd *cobra.Command, args []string) {
 
-		app.logger.Debug("start unmarshal configuration")
-		err := viper.Unmarshal(app.config)
-		if err != nil {
-			logrus.WithError(err).Error("unmarshal config error")
-			os.Exit(1)
+		app.loadConfig()
+
+		if app.isPreforkMaster() {
+			app.runPreforkMaster()
 			return
 		}
 
-		app.logger.Debug("unmarshal configuration completed")
-		app.logger.WithField("config", app.config).Info("loaded configuration")
-
 		app.init()
 
 		app.start()
@@ -86,6 +102,8 @@ func (app *Application) Launch() {
 		app.waitSignal()
 	}
 
+	app.initTaskCommand()
+
 	app.logger.Debug("initialized root command")
 
 	app.logger.Debug("execute root command")
@@ -93,10 +111,25 @@ func (app *Application) Launch() {
 	cmd.Execute()
 }
 
+func (app *Application) loadConfig() {
+
+	app.logger.Debug("start unmarshal configuration")
+	err := viper.Unmarshal(app.config)
+	if err != nil {
+		logrus.WithError(err).Error("unmarshal config error")
+		os.Exit(1)
+		return
+	}
+
+	app.logger.Debug("unmarshal configuration completed")
+	app.logger.WithField("config", app.config).Info("loaded configuration")
+}
+
 func (app *Application) start() {
 
-	app.startMySQLClient()
-	app.startRedisClient()
+	app.initConnections()
+	app.monitor.start()
+	app.startAuthorization()
 
 	app.logger.Debug("start services")
 	for _, svc := range app.services {
@@ -132,9 +165,51 @@ func (app *Application) close() {
 		app.events.OnClose(app)
 	}
 
+	if app.monitor != nil {
+		app.monitor.close()
+	}
+
+	if app.policy != nil {
+		app.policy.Close()
+	}
+
+	app.releaseConnections()
+
 	os.Exit(0)
 }
 
+func (app *Application) releaseConnections() {
+
+	app.logger.Debug("start to release connections")
+
+	for key := range app.config.MySQL {
+		if err := database.Disconnect(key); err != nil {
+			app.logger.WithError(err).WithField("key", key).Warn("disconnect mysql error")
+		}
+	}
+
+	for key := range app.config.Redis {
+		if err := cache.Disconnect(key); err != nil {
+			app.logger.WithError(err).WithField("key", key).Warn("disconnect redis error")
+		}
+	}
+
+	for key := range app.config.RPCClients {
+		if err := client.Disconnect(key); err != nil {
+			app.logger.WithError(err).WithField("key", key).Warn("disconnect rpc client error")
+		}
+	}
+
+	if app.accessLogFile != nil {
+		if err := app.accessLogFile.Close(); err != nil {
+			app.logger.WithError(err).Warn("close access log file error")
+		}
+		app.accessLogFile = nil
+	}
+
+	app.logger.Debug("connections released")
+}
+
 type WaitingToDo func()
 
 func (app *Application) waitSignal() {
@@ -156,60 +231,21 @@ exit:
 	return
 }
 
-func (app *Application) startMySQLClient() {
-
-	app.logger.Debug("start to connect mysql clients")
-	database.SetLogger(app.logger.Logger)
-
-	for key, mysqlConfig := range app.config.MySQL {
-
-		err := database.Connect(key, database.NewMySQLConfig(
-			database.MySQLHost(mysqlConfig.GetHost()),
-			database.MySQLPort(mysqlConfig.GetPort()),
-			database.MySQLUsername(mysqlConfig.GetUsername()),
-			database.MySQLPassword(mysqlConfig.GetPassword()),
-			database.MySQLDatabase(mysqlConfig.GetDatabase()),
-			database.MySQLLogMode(mysqlConfig.GetLogMode()),
-		))
-
-		if err != nil {
-			logrus.WithError(err).WithField("key", key).Error("connect mysql error")
-		}
-	}
-
-	app.logger.Debug("mysql client connected")
-}
-
-func (app *Application) startRedisClient() {
-
-	app.logger.Debug("start to connect redis clients")
-	cache.SetLogger(app.logger.Logger)
-
-	for key, redisConfig := range app.config.Redis {
-
-		err := cache.Connect(key, cache.NewRedisConfig(
-			cache.RedisHost(redisConfig.GetHost()),
-			cache.RedisPort(redisConfig.GetPort()),
-			cache.RedisPassword(redisConfig.GetPassword()),
-			cache.RedisDatabase(redisConfig.GetDatabase()),
-		))
-
-		if err != nil {
-			logrus.WithError(err).WithField("key", key).Error("connect mysql error")
-		}
-	}
-
-	app.logger.Debug("redis clients connected")
-}
-
 func (app *Application) init() {
 
 	app.logger.Debug("start to init application")
 	app.initRandomSeed()
 	app.initServiceId()
+	app.initPreforkWorker()
 	app.initLogger()
+	app.initRuntime()
+	app.initI18n()
+	app.initJWT()
+	app.initAuthorization()
 	app.initWebService()
+	app.initJWKSHandler()
 	app.initRPCService()
+	app.initRPCGateway()
 
 	if app.events.OnInit != nil {
 		app.events.OnInit(app)
@@ -228,6 +264,12 @@ func (app *Application) initWebService() {
 
 	app.logger.Info("start to init web service")
 
+	if err := codec.SetDefaultFormat(codec.Format(app.config.Web.GetResponseFormat())); err != nil {
+		app.logger.WithError(err).Error("set default response format error")
+		os.Exit(1)
+		return
+	}
+
 	app.services = append(app.services,
 		service.NewGinService(app.logger,
 			service.NewGinConfig(
@@ -236,16 +278,67 @@ func (app *Application) initWebService() {
 						service.GinListenConfigIP(app.config.Web.IP),
 						service.GinListenConfigPort(app.config.Web.Port),
 						service.GinListenConfigReadWriteTimeout(app.config.Web.ReadWriteTimeout),
+						service.GinListenConfigReusePort(IsPreforkWorker()),
 					),
 				),
 				service.GinConfigWebServiceMode(service.WebServiceMode(app.config.Web.Mode)),
+				service.GinConfigRecoveryOptions(app.getRecoveryOptions()...),
+				service.GinConfigAccessLogOptions(app.getAccessLogOptions()...),
 			),
 		),
 	)
 
+	if app.config.Web.HealthCheckPath != "" {
+		app.logger.WithField("path", app.config.Web.HealthCheckPath).Info("register health check handler")
+		app.GetWebService().GetEngine().GET(app.config.Web.HealthCheckPath, app.HealthCheckHandler)
+	}
+
 	app.logger.Debug("init web service completed")
 }
 
+func (app *Application) getRecoveryOptions() []recovery.Option {
+
+	options := make([]recovery.Option, 0, len(app.reporters))
+	for _, reporter := range app.reporters {
+		options = append(options, recovery.AddReporter(reporter))
+	}
+
+	return options
+}
+
+func (app *Application) getAccessLogOptions() []request.Option {
+
+	config := app.config.Web.AccessLog
+
+	options := []request.Option{
+		request.AccessLogFormat(request.Format(config.Format)),
+		request.AccessLogSkipPaths(config.SkipPaths...),
+		request.AccessLogSlowThresholds(config.SlowThreshold, config.VerySlowThreshold),
+		request.AccessLogCaptureHeaders(config.CaptureHeaders),
+		request.AccessLogCaptureBody(config.CaptureRequestBody, config.CaptureResponseBody, config.MaxBodySize),
+		request.AccessLogRedactHeaders(config.RedactHeaders...),
+		request.AccessLogRedactFields(config.RedactFields...),
+	}
+
+	if app.config.Web.HealthCheckPath != "" {
+		options = append(options, request.AccessLogSkipPaths(app.config.Web.HealthCheckPath))
+	}
+
+	if config.File != "" {
+
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			app.logger.WithError(err).WithField("file", config.File).Error("open access log file error")
+			os.Exit(1)
+		}
+
+		app.accessLogFile = file
+		options = append(options, request.AccessLogOutput(file))
+	}
+
+	return options
+}
+
 func (app *Application) GetWebService() *service.Gin {
 
 	for _, svc := range app.services {
@@ -294,8 +387,28 @@ func (app *Application) initRPCService() {
 					service.NewRPCListenConfig(
 						service.RPCListenConfigIP(app.config.RPC.IP),
 						service.RPCListenConfigPort(app.config.RPC.Port),
+						service.RPCListenConfigReusePort(IsPreforkWorker()),
 					),
 				),
+				service.RPCConfigReadWriteTimeout(app.config.RPC.ReadWriteTimeout),
+				service.RPCConfigMaxMsgSize(app.config.RPC.MaxRecvMsgSize, app.config.RPC.MaxSendMsgSize),
+				service.RPCConfigMaxConcurrentStreams(app.config.RPC.MaxConcurrentStreams),
+				service.RPCConfigKeepalive(
+					keepalive.ServerParameters{
+						MaxConnectionIdle:     app.config.RPC.Keepalive.MaxConnectionIdle,
+						MaxConnectionAge:      app.config.RPC.Keepalive.MaxConnectionAge,
+						MaxConnectionAgeGrace: app.config.RPC.Keepalive.MaxConnectionAgeGrace,
+						Time:                  app.config.RPC.Keepalive.Time,
+						Timeout:               app.config.RPC.Keepalive.Timeout,
+					},
+					keepalive.EnforcementPolicy{
+						MinTime:             app.config.RPC.Keepalive.MinTime,
+						PermitWithoutStream: app.config.RPC.Keepalive.PermitWithoutStream,
+					},
+				),
+				service.RPCConfigReflection(app.config.RPC.Reflection),
+				service.RPCConfigUnaryInterceptors(app.unaryInterceptors...),
+				service.RPCConfigStreamInterceptors(app.streamInterceptors...),
 			),
 		),
 	)
@@ -303,6 +416,34 @@ func (app *Application) initRPCService() {
 	app.logger.Debug("init rpc service completed")
 }
 
+func (app *Application) initRPCGateway() {
+
+	if !app.config.RPC.Gateway.Enable {
+		return
+	}
+
+	webService := app.GetWebService()
+	rpcService := app.GetRPCService()
+	if webService == nil || rpcService == nil {
+		app.logger.Warn("rpc gateway requires both web service and rpc service enabled")
+		return
+	}
+
+	conn, err := rpcService.GetInProcessConnection()
+	if err != nil {
+		app.logger.WithError(err).Error("dial in-process rpc connection error")
+		os.Exit(1)
+		return
+	}
+
+	gateway.NewGateway(rpcService.GetRPCConnection(), conn,
+		gateway.PathPrefix(app.config.RPC.Gateway.GetPathPrefix()),
+		gateway.MaxBodySize(app.config.RPC.Gateway.MaxBodySize),
+	).Mount(webService.GetEngine())
+
+	app.logger.WithField("pathPrefix", app.config.RPC.Gateway.GetPathPrefix()).Info("rpc gateway mounted")
+}
+
 func (app *Application) initLogger() {
 
 	app.logger.Info("start to init logger")
@@ -326,7 +467,7 @@ func (app *Application) initServiceId() {
 		app.logger.Infof("serviceId: %d", app.config.GetServiceId())
 	}()
 
-	serviceIdString, err = cmd.GetRootCommand().Flags().GetString(cmd.FlagServiceId)
+	serviceIdString, err = cmd.GetRootCommand().PersistentFlags().GetString(cmd.FlagServiceId)
 	if err != nil || len(serviceIdString) <= 0 {
 		app.logger.WithError(err).Warn("get service id failed")
 		app.config.ServiceId = 0
@@ -381,6 +522,32 @@ func SetApplicationLogger(logger *logrus.Entry) ApplicationOption {
 	}
 }
 
+// AddApplicationPanicReporter reports the panics recovered from web service
+func AddApplicationPanicReporter(reporter recovery.Reporter) ApplicationOption {
+
+	return func(app *Application) {
+		if reporter != nil {
+			app.reporters = append(app.reporters, reporter)
+		}
+	}
+}
+
+// AddApplicationUnaryServerInterceptors appends interceptors after the standard chain of rpc service
+func AddApplicationUnaryServerInterceptors(interceptors ...grpc.UnaryServerInterceptor) ApplicationOption {
+
+	return func(app *Application) {
+		app.unaryInterceptors = append(app.unaryInterceptors, interceptors...)
+	}
+}
+
+// AddApplicationStreamServerInterceptors appends interceptors after the standard chain of rpc service
+func AddApplicationStreamServerInterceptors(interceptors ...grpc.StreamServerInterceptor) ApplicationOption {
+
+	return func(app *Application) {
+		app.streamInterceptors = append(app.streamInterceptors, interceptors...)
+	}
+}
+
 type Event func(app *Application)
 
 type Events struct {
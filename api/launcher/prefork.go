@@ -0,0 +1,400 @@
+package launcher
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	launcherConfig "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/config"
+)
+
+// EnvPreforkWorkerIndex is set by master process to tell the process is a prefork worker.
+const EnvPreforkWorkerIndex = "LAUNCHER_PREFORK_WORKER_INDEX"
+
+// EnvPreforkWorkerGeneration is 0 or 1, the new worker of rolling restart has the other generation of the old one,
+// so they have different serviceId while both are running.
+const EnvPreforkWorkerGeneration = "LAUNCHER_PREFORK_WORKER_GENERATION"
+
+type preforkWorker struct {
+	index      int
+	generation int
+	cmd        *exec.Cmd
+	exited     chan struct{}
+	err        error
+}
+
+type preforkMaster struct {
+	app     *Application
+	config  launcherConfig.PreforkConfig
+	logger  *logrus.Entry
+	lock    sync.Mutex
+	workers map[int]*preforkWorker
+	exits   chan *preforkWorker
+	closing bool
+	rolling bool
+}
+
+// IsPreforkWorker returns whether current process is forked by prefork master.
+func IsPreforkWorker() bool {
+
+	_, ok := getPreforkWorkerIndex()
+	return ok
+}
+
+func getPreforkWorkerIndex() (int, bool) {
+
+	indexString, ok := os.LookupEnv(EnvPreforkWorkerIndex)
+	if !ok {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(indexString)
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
+
+func getPreforkWorkerGeneration() int {
+
+	generation, err := strconv.Atoi(os.Getenv(EnvPreforkWorkerGeneration))
+	if err != nil {
+		return 0
+	}
+
+	return generation
+}
+
+func (app *Application) isPreforkMaster() bool {
+
+	return app.config.Prefork.Enable && !IsPreforkWorker()
+}
+
+// workerIndexBits is the low bits of serviceId reserved for worker index
+func workerIndexBits(workers uint16) uint {
+
+	if workers <= 1 {
+		return 0
+	}
+
+	return uint(bits.Len16(workers - 1))
+}
+
+// deriveWorkerServiceId makes every worker have a distinct serviceId, so the snowflake ids created by workers not collide.
+// The low bits are the worker index, and the bit above them is the generation,
+// so the serviceId of master must fit in the rest bits, e.g. it must be less than 2048 for 16 workers.
+func deriveWorkerServiceId(serviceId uint16, index, generation int, workers uint16) (uint16, error) {
+
+	indexBits := workerIndexBits(workers)
+
+	if index < 0 || index >= int(workers) {
+		return 0, fmt.Errorf("prefork worker index %d out of range [0, %d)", index, workers)
+	}
+
+	// one bit is the generation
+	if indexBits >= 16 {
+		return 0, fmt.Errorf("too many prefork workers %d, it must be at most %d", workers, 1<<15)
+	}
+
+	if generation != 0 && generation != 1 {
+		return 0, fmt.Errorf("prefork worker generation %d is not 0 or 1", generation)
+	}
+
+	if uint32(serviceId)<<(indexBits+1) > math.MaxUint16 {
+		return 0, fmt.Errorf("serviceId %d is too large for %d prefork workers, it must be less than %d",
+			serviceId, workers, 1<<(15-indexBits))
+	}
+
+	return serviceId<<(indexBits+1) | uint16(generation)<<indexBits | uint16(index), nil
+}
+
+func (app *Application) initPreforkWorker() {
+
+	index, ok := getPreforkWorkerIndex()
+	if !ok {
+		return
+	}
+
+	generation := getPreforkWorkerGeneration()
+
+	serviceId, err := deriveWorkerServiceId(app.config.GetServiceId(), index, generation, app.config.Prefork.GetWorkers())
+	if err != nil {
+		app.logger.WithError(err).Error("derive prefork worker serviceId error")
+		os.Exit(1)
+		return
+	}
+
+	app.logger.WithField("workerIndex", index).
+		WithField("workerGeneration", generation).
+		WithField("masterServiceId", app.config.GetServiceId()).
+		Infof("prefork worker serviceId: %d", serviceId)
+
+	app.config.ServiceId = serviceId
+}
+
+func (app *Application) runPreforkMaster() {
+
+	app.initRandomSeed()
+	app.initServiceId()
+	app.initLogger()
+
+	// check the serviceId before fork, so the invalid config is not restarted by master again and again
+	if _, err := deriveWorkerServiceId(app.config.GetServiceId(), 0, 0, app.config.Prefork.GetWorkers()); err != nil {
+		app.logger.WithError(err).Error("invalid prefork config")
+		os.Exit(1)
+		return
+	}
+
+	master := &preforkMaster{
+		app:     app,
+		config:  app.config.Prefork,
+		logger:  app.logger.WithField("role", "preforkMaster"),
+		workers: make(map[int]*preforkWorker),
+		exits:   make(chan *preforkWorker, app.config.Prefork.GetWorkers()),
+	}
+
+	os.Exit(master.run())
+}
+
+func (master *preforkMaster) run() int {
+
+	workers := int(master.config.GetWorkers())
+	master.logger.WithField("workers", workers).Info("start prefork master")
+
+	chanSignal := make(chan os.Signal, 1)
+	signal.Notify(chanSignal, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	for index := 0; index < workers; index++ {
+
+		if _, err := master.spawn(index, 0); err != nil {
+			master.logger.WithError(err).WithField("workerIndex", index).Error("start worker error")
+			master.stop(syscall.SIGTERM)
+			return 1
+		}
+	}
+
+	for {
+		select {
+		case worker := <-master.exits:
+			master.onWorkerExit(worker)
+
+		case sig := <-chanSignal:
+			master.logger.Infof("Received signal: %d", sig)
+
+			switch sig {
+			case syscall.SIGHUP:
+				go master.rollingRestart()
+			case syscall.SIGUSR1, syscall.SIGUSR2:
+				// workers treat them as shutdown, so they are not forwarded
+				master.logger.Warnf("signal %d is ignored by prefork master", sig)
+			default:
+				master.stop(sig)
+				master.logger.Info("prefork master exited")
+				return 0
+			}
+		}
+	}
+}
+
+func (master *preforkMaster) spawn(index, generation int) (*preforkWorker, error) {
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	command := exec.Command(executable, os.Args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%d", EnvPreforkWorkerIndex, index),
+		fmt.Sprintf("%s=%d", EnvPreforkWorkerGeneration, generation))
+	command.SysProcAttr = workerSysProcAttr()
+
+	if err = command.Start(); err != nil {
+		return nil, err
+	}
+
+	worker := &preforkWorker{
+		index:      index,
+		generation: generation,
+		cmd:        command,
+		exited:     make(chan struct{}),
+	}
+
+	master.lock.Lock()
+	master.workers[index] = worker
+	master.lock.Unlock()
+
+	master.logger.WithField("workerIndex", index).
+		WithField("workerGeneration", generation).
+		WithField("pid", command.Process.Pid).
+		Info("worker started")
+
+	go func() {
+		worker.err = command.Wait()
+		close(worker.exited)
+		master.exits <- worker
+	}()
+
+	return worker, nil
+}
+
+func (master *preforkMaster) onWorkerExit(worker *preforkWorker) {
+
+	master.lock.Lock()
+	current := master.workers[worker.index] == worker
+	closing := master.closing
+	master.lock.Unlock()
+
+	logger := master.logger.WithField("workerIndex", worker.index).WithField("pid", worker.cmd.Process.Pid)
+
+	if closing || !current {
+		logger.WithError(worker.err).Info("worker exited")
+		return
+	}
+
+	logger.WithError(worker.err).Error("worker crashed, restart later")
+
+	time.AfterFunc(master.config.GetRestartDelay(), func() {
+
+		master.lock.Lock()
+		closing := master.closing
+		master.lock.Unlock()
+
+		if closing {
+			return
+		}
+
+		// the crashed worker has exited, so the new one takes its generation
+		if _, err := master.spawn(worker.index, worker.generation); err != nil {
+			logger.WithError(err).Error("restart worker error")
+			master.exits <- worker
+		}
+	})
+}
+
+// rollingRestart replaces the workers one by one, the old worker will be stopped after the new one is ready.
+func (master *preforkMaster) rollingRestart() {
+
+	master.lock.Lock()
+	if master.rolling || master.closing {
+		master.lock.Unlock()
+		master.logger.Warn("rolling restart is in progress or master is closing, ignored")
+		return
+	}
+	master.rolling = true
+	indexes := make([]int, 0, len(master.workers))
+	for index := range master.workers {
+		indexes = append(indexes, index)
+	}
+	master.lock.Unlock()
+
+	defer func() {
+		master.lock.Lock()
+		master.rolling = false
+		master.lock.Unlock()
+	}()
+
+	master.logger.Info("start rolling restart workers")
+
+	for _, index := range indexes {
+
+		master.lock.Lock()
+		oldWorker := master.workers[index]
+		closing := master.closing
+		master.lock.Unlock()
+
+		if closing {
+			return
+		}
+
+		logger := master.logger.WithField("workerIndex", index)
+
+		// the old worker is running until the new one is ready, so they must have different generations
+		generation := 0
+		if oldWorker != nil {
+			generation = nextWorkerGeneration(oldWorker.generation)
+		}
+
+		newWorker, err := master.spawn(index, generation)
+		if err != nil {
+			logger.WithError(err).Error("start new worker error, stop rolling restart")
+			return
+		}
+
+		select {
+		case <-newWorker.exited:
+			logger.Error("new worker exited before ready, stop rolling restart")
+			master.lock.Lock()
+			if master.workers[index] == newWorker {
+				master.workers[index] = oldWorker
+			}
+			master.lock.Unlock()
+			return
+		case <-time.After(master.config.GetReadyDelay()):
+		}
+
+		if oldWorker != nil {
+			master.terminate(oldWorker, syscall.SIGTERM)
+		}
+	}
+
+	master.logger.Info("rolling restart workers completed")
+}
+
+func nextWorkerGeneration(generation int) int {
+	return 1 - generation
+}
+
+func (master *preforkMaster) stop(sig os.Signal) {
+
+	master.lock.Lock()
+	master.closing = true
+	workers := make([]*preforkWorker, 0, len(master.workers))
+	for _, worker := range master.workers {
+		workers = append(workers, worker)
+	}
+	master.lock.Unlock()
+
+	var wait sync.WaitGroup
+	for _, worker := range workers {
+
+		wait.Add(1)
+		go func(worker *preforkWorker) {
+			defer wait.Done()
+			master.terminate(worker, sig)
+		}(worker)
+	}
+
+	wait.Wait()
+}
+
+// terminate sends signal to worker and waits it exit, the worker will be killed after stop timeout.
+func (master *preforkMaster) terminate(worker *preforkWorker, sig os.Signal) {
+
+	logger := master.logger.WithField("workerIndex", worker.index).WithField("pid", worker.cmd.Process.Pid)
+
+	if err := worker.cmd.Process.Signal(sig); err != nil {
+		logger.WithError(err).Debug("signal worker error")
+	}
+
+	select {
+	case <-worker.exited:
+	case <-time.After(master.config.GetStopTimeout()):
+		logger.Warn("worker not exited after stop timeout, kill it")
+		_ = worker.cmd.Process.Kill()
+		<-worker.exited
+	}
+}
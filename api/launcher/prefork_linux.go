@@ -0,0 +1,13 @@
+package launcher
+
+import (
+	"syscall"
+)
+
+// workers will receive SIGTERM when master process died
+func workerSysProcAttr() *syscall.SysProcAttr {
+
+	return &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package launcher
+
+import (
+	"syscall"
+)
+
+func workerSysProcAttr() *syscall.SysProcAttr {
+
+	return nil
+}
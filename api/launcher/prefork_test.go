@@ -0,0 +1,101 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveWorkerServiceId(t *testing.T) {
+
+	tests := []struct {
+		serviceId uint16
+		workers   uint16
+		valid     bool
+	}{
+		{serviceId: 0, workers: 1, valid: true},
+		{serviceId: 32767, workers: 1, valid: true},
+		{serviceId: 32768, workers: 1, valid: false},
+		{serviceId: 353, workers: 16, valid: true},
+		{serviceId: 2047, workers: 16, valid: true},
+		{serviceId: 2048, workers: 16, valid: false},
+		{serviceId: 1023, workers: 17, valid: true},
+		{serviceId: 1024, workers: 17, valid: false},
+		{serviceId: 0, workers: 32768, valid: true},
+		{serviceId: 1, workers: 32768, valid: false},
+		{serviceId: 0, workers: 32769, valid: false},
+	}
+
+	for _, test := range tests {
+
+		ids := make(map[uint16]int, test.workers)
+		for index := 0; index < int(test.workers); index++ {
+
+			id, err := deriveWorkerServiceId(test.serviceId, index, 0, test.workers)
+			if !test.valid {
+				assert.Error(t, err, test)
+				break
+			}
+
+			assert.NoError(t, err, test)
+			if previous, exist := ids[id]; exist {
+				t.Fatalf("serviceId %d: workers %d and %d have the same id %d", test.serviceId, previous, index, id)
+			}
+			ids[id] = index
+		}
+	}
+
+	_, err := deriveWorkerServiceId(1, 4, 0, 4)
+	assert.Error(t, err)
+
+	_, err = deriveWorkerServiceId(1, 0, 2, 4)
+	assert.Error(t, err)
+}
+
+// the ids of workers are distinct across services too, so the services with different serviceId never collide
+func TestDeriveWorkerServiceId_Distinct(t *testing.T) {
+
+	const workers = 16
+
+	ids := make(map[uint16]bool, 1<<16)
+	for serviceId := 0; serviceId < 1<<(15-workerIndexBits(workers)); serviceId++ {
+		for index := 0; index < workers; index++ {
+			for generation := 0; generation <= 1; generation++ {
+
+				id, err := deriveWorkerServiceId(uint16(serviceId), index, generation, workers)
+				assert.NoError(t, err)
+
+				if ids[id] {
+					t.Fatalf("duplicated id %d of serviceId %d worker %d generation %d", id, serviceId, index, generation)
+				}
+				ids[id] = true
+			}
+		}
+	}
+}
+
+// the new worker of rolling restart runs with the old one of the same index until the old one exited
+func TestDeriveWorkerServiceId_RollingRestart(t *testing.T) {
+
+	const (
+		serviceId = 353
+		workers   = 4
+	)
+
+	for index := 0; index < workers; index++ {
+
+		generation := 0
+		for restart := 0; restart < 3; restart++ {
+
+			oldId, err := deriveWorkerServiceId(serviceId, index, generation, workers)
+			assert.NoError(t, err)
+
+			generation = nextWorkerGeneration(generation)
+
+			newId, err := deriveWorkerServiceId(serviceId, index, generation, workers)
+			assert.NoError(t, err)
+
+			assert.NotEqual(t, oldId, newId, "worker %d restart %d", index, restart)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package launcher
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/cgroup"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/gctuner"
+)
+
+func (app *Application) initRuntime() {
+
+	logger := app.logger.WithField("component", "runtime")
+
+	app.initMaxProcs(logger)
+	app.initGC(logger)
+}
+
+func (app *Application) initMaxProcs(logger *logrus.Entry) {
+
+	previous := runtime.GOMAXPROCS(0)
+
+	if maxProcs := app.config.Runtime.GetMaxProcs(); maxProcs > 0 {
+		runtime.GOMAXPROCS(maxProcs)
+		logger.WithField("previous", previous).Infof("GOMAXPROCS set to %d by configuration", maxProcs)
+		return
+	}
+
+	if value, exist := os.LookupEnv("GOMAXPROCS"); exist {
+		logger.Infof("GOMAXPROCS set to %s by environment variable", value)
+		return
+	}
+
+	quota, ok, err := cgroup.CPUQuota()
+	if err != nil {
+		logger.WithError(err).Warn("read cgroup cpu quota error, GOMAXPROCS not changed")
+		return
+	}
+
+	if !ok {
+		logger.Infof("cpu quota not limited, GOMAXPROCS is %d", previous)
+		return
+	}
+
+	maxProcs := int(math.Floor(quota))
+	if IsPreforkWorker() {
+		maxProcs = maxProcs / int(app.config.Prefork.GetWorkers())
+	}
+	if maxProcs < 1 {
+		maxProcs = 1
+	}
+
+	runtime.GOMAXPROCS(maxProcs)
+	logger.WithField("cpuQuota", quota).
+		WithField("previous", previous).
+		Infof("GOMAXPROCS set to %d by cgroup cpu quota", maxProcs)
+}
+
+func (app *Application) initGC(logger *logrus.Entry) {
+
+	if gcPercent := app.config.Runtime.GetGCPercent(); gcPercent != 0 {
+		debug.SetGCPercent(gcPercent)
+		logger.Infof("GOGC set to %d by configuration", gcPercent)
+		return
+	}
+
+	if value, exist := os.LookupEnv("GOGC"); exist {
+		logger.Infof("GOGC set to %s by environment variable", value)
+		return
+	}
+
+	memoryLimit := app.config.Runtime.GetMemoryLimit()
+	source := "configuration"
+
+	if memoryLimit == 0 {
+
+		limit, ok, err := cgroup.MemoryLimit()
+		if err != nil {
+			logger.WithError(err).Warn("read cgroup memory limit error, GC not tuned")
+			return
+		}
+
+		if !ok {
+			logger.Info("memory not limited, GC not tuned")
+			return
+		}
+
+		memoryLimit = limit
+		source = "cgroup"
+	}
+
+	if IsPreforkWorker() {
+		memoryLimit = memoryLimit / uint64(app.config.Prefork.GetWorkers())
+	}
+
+	heapTarget := uint64(float64(memoryLimit) * app.config.Runtime.GetMemoryLimitRatio())
+
+	gctuner.NewTuner(heapTarget).Start()
+
+	logger.WithField("memoryLimit", memoryLimit).
+		WithField("source", source).
+		WithField("heapTarget", heapTarget).
+		Info("GC tuned by memory limit")
+}
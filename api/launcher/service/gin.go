@@ -8,6 +8,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/log/request"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/recovery"
 )
 
 const ServiceNameGin = "gin"
@@ -27,7 +28,9 @@ func NewGinService(logger *logrus.Entry, config *GinConfig) *Gin {
 		logger: logger,
 	}
 
-	g.engine = gin.Default()
+	g.engine = gin.New()
+	g.initRequestLogger()
+	g.engine.Use(recovery.Recovery(append([]recovery.Option{recovery.Logger(logger)}, config.RecoveryOptions...)...))
 
 	return g
 }
@@ -36,11 +39,9 @@ func (g *Gin) OnStart() error {
 
 	g.printConfig()
 	g.initLogLevel()
-	g.initRequestLogger()
 	g.initHTTPServer()
-	g.startHTTPServer()
 
-	return nil
+	return g.startHTTPServer()
 }
 
 func (g *Gin) OnStop() error {
@@ -72,7 +73,7 @@ func (g *Gin) initLogLevel() {
 
 func (g *Gin) initRequestLogger() {
 	g.logger.Debug("start to register log middleware")
-	g.engine.Use(request.ReqLoggerMiddleware())
+	g.engine.Use(request.AccessLogger(g.logger, g.config.AccessLogOptions...))
 	g.logger.Debug("register log middleware succeed")
 }
 
@@ -93,14 +94,22 @@ func (g *Gin) initHTTPServer() {
 	g.logger.Debug("init restful api listener succeed")
 }
 
-func (g *Gin) startHTTPServer() {
+func (g *Gin) startHTTPServer() error {
 	g.logger.Infof("start server listening")
+
+	listener, err := listen(g.httpServer.Addr, g.config.ListenConfig.IsReusePort())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %s", g.httpServer.Addr, err)
+	}
+
 	go func() {
-		err := g.httpServer.ListenAndServe()
+		err := g.httpServer.Serve(listener)
 		if err != nil && !g.isClosing {
 			g.logger.Errorf("listen error: %v", err)
 		}
 	}()
+
+	return nil
 }
 
 func (g *Gin) markClosing() {
@@ -3,6 +3,9 @@ package service
 import (
 	"fmt"
 	"time"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/log/request"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/middleware/recovery"
 )
 
 const (
@@ -17,6 +20,7 @@ type GinListenConfig struct {
 	IP               string
 	Port             uint16
 	ReadWriteTimeout time.Duration
+	ReusePort        bool
 }
 
 func GinListenConfigIP(ip string) GinListenOption {
@@ -40,6 +44,13 @@ func GinListenConfigReadWriteTimeout(duration time.Duration) GinListenOption {
 	}
 }
 
+func GinListenConfigReusePort(reusePort bool) GinListenOption {
+
+	return func(config *GinListenConfig) {
+		config.ReusePort = reusePort
+	}
+}
+
 func NewGinListenConfig(options ...GinListenOption) *GinListenConfig {
 
 	config := &GinListenConfig{}
@@ -82,6 +93,11 @@ func (config GinListenConfig) GetReadWriteTimeout() time.Duration {
 	return config.ReadWriteTimeout
 }
 
+func (config GinListenConfig) IsReusePort() bool {
+
+	return config.ReusePort
+}
+
 // Gin Service Mode
 type WebServiceMode string
 
@@ -93,8 +109,10 @@ const (
 type GinConfigOption func(config *GinConfig)
 
 type GinConfig struct {
-	ListenConfig   *GinListenConfig
-	WebServiceMode WebServiceMode
+	ListenConfig     *GinListenConfig
+	WebServiceMode   WebServiceMode
+	RecoveryOptions  []recovery.Option
+	AccessLogOptions []request.Option
 }
 
 func NewGinConfig(options ...GinConfigOption) *GinConfig {
@@ -133,3 +151,17 @@ func GinConfigWebServiceMode(mode WebServiceMode) GinConfigOption {
 		config.WebServiceMode = mode
 	}
 }
+
+func GinConfigRecoveryOptions(options ...recovery.Option) GinConfigOption {
+	return func(config *GinConfig) {
+
+		config.RecoveryOptions = append(config.RecoveryOptions, options...)
+	}
+}
+
+func GinConfigAccessLogOptions(options ...request.Option) GinConfigOption {
+	return func(config *GinConfig) {
+
+		config.AccessLogOptions = append(config.AccessLogOptions, options...)
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"net"
+)
+
+// listen creates tcp listener, with reusePort the same address can be listened by multiple processes.
+func listen(address string, reusePort bool) (net.Listener, error) {
+
+	if !reusePort {
+		return net.Listen("tcp", address)
+	}
+
+	listenConfig := &net.ListenConfig{
+		Control: reusePortControl,
+	}
+
+	return listenConfig.Listen(context.Background(), "tcp", address)
+}
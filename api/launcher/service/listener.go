@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+var errorListenerClosed = errors.New("in-process listener closed")
+
+// pipeListener is a net.Listener in memory, every Dial creates a pair of connected net.Pipe,
+// it serves the in-process connection of rpc service without network.
+type pipeListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (listener *pipeListener) Accept() (net.Conn, error) {
+
+	select {
+	case conn := <-listener.conns:
+		return conn, nil
+	case <-listener.closed:
+		return nil, errorListenerClosed
+	}
+}
+
+func (listener *pipeListener) Close() error {
+
+	listener.closeOnce.Do(func() {
+		close(listener.closed)
+	})
+
+	return nil
+}
+
+func (listener *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// Dial returns the client side of connection after the server side is accepted.
+func (listener *pipeListener) Dial() (net.Conn, error) {
+
+	server, client := net.Pipe()
+
+	select {
+	case listener.conns <- server:
+		return client, nil
+	case <-listener.closed:
+		_ = server.Close()
+		_ = client.Close()
+		return nil, errorListenerClosed
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (pipeAddr) String() string {
+	return "in-process"
+}
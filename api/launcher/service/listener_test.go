@@ -0,0 +1,49 @@
+package service
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestPipeListener(t *testing.T) {
+
+	listener := newPipeListener()
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "in-process", grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	reply, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, reply.GetStatus())
+
+	server.Stop()
+
+	_, err = listener.Dial()
+	assert.Equal(t, errorListenerClosed, err)
+
+	_, err = listener.Accept()
+	assert.Equal(t, errorListenerClosed, err)
+	assert.NoError(t, listener.Close())
+}
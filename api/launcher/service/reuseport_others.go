@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package service
+
+import (
+	"errors"
+	"syscall"
+)
+
+func reusePortControl(network, address string, conn syscall.RawConn) error {
+
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
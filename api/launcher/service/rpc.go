@@ -3,9 +3,14 @@ package service
 import (
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/interceptor"
 )
 
 const ServiceNameRPC = "rpc"
@@ -14,14 +19,40 @@ type RPC struct {
 	server *grpc.Server
 	logger *logrus.Entry
 	config *RPCConfig
+
+	inProcessListener *pipeListener
+	inProcessLock     sync.Mutex
+	inProcessConn     *grpc.ClientConn
 }
 
 func NewRPCService(logger *logrus.Entry, config *RPCConfig) *RPC {
 
+	// the interceptors of application are appended after the standard chain,
+	// so they can get request id from context and their panics and errors are handled.
+	unaryInterceptors := interceptor.DefaultUnaryServerInterceptors(logger)
+	if config.ReadWriteTimeout > 0 {
+		unaryInterceptors = append(unaryInterceptors, interceptor.UnaryServerTimeout(config.ReadWriteTimeout))
+	}
+	unaryInterceptors = append(unaryInterceptors, config.UnaryInterceptors...)
+	streamInterceptors := append(interceptor.DefaultStreamServerInterceptors(logger), config.StreamInterceptors...)
+
+	options := append(config.GetServerOptions(),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+
+	server := grpc.NewServer(options...)
+
+	if config.Reflection {
+		reflection.Register(server)
+		logger.Warn("rpc reflection service registered, it should be disabled in production")
+	}
+
 	return &RPC{
-		server: grpc.NewServer(),
-		logger: logger,
-		config: config,
+		server:            server,
+		logger:            logger,
+		config:            config,
+		inProcessListener: newPipeListener(),
 	}
 }
 
@@ -33,7 +64,7 @@ func (r *RPC) OnStart() error {
 
 	r.logger.WithField("listenAddr", listenAddr).Info("starting rpc service")
 
-	listener, err := net.Listen("tcp", listenAddr)
+	listener, err := listen(listenAddr, r.config.ListenConfig.IsReusePort())
 
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %s", listenAddr, err)
@@ -43,12 +74,24 @@ func (r *RPC) OnStart() error {
 		_ = r.server.Serve(listener)
 	}()
 
+	go func() {
+		_ = r.server.Serve(r.inProcessListener)
+	}()
+
 	return nil
 }
 
 func (r *RPC) OnStop() error {
 
 	r.server.GracefulStop()
+
+	r.inProcessLock.Lock()
+	defer r.inProcessLock.Unlock()
+
+	if r.inProcessConn != nil {
+		_ = r.inProcessConn.Close()
+	}
+
 	return nil
 }
 
@@ -61,3 +104,29 @@ func (r *RPC) GetRPCConnection() *grpc.Server {
 
 	return r.server
 }
+
+// GetInProcessConnection returns the connection to the server in the same process without network,
+// the calls are handled by the server interceptors as well, e.g. used by gateway.
+func (r *RPC) GetInProcessConnection() (*grpc.ClientConn, error) {
+
+	r.inProcessLock.Lock()
+	defer r.inProcessLock.Unlock()
+
+	if r.inProcessConn != nil {
+		return r.inProcessConn, nil
+	}
+
+	conn, err := grpc.Dial("in-process",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return r.inProcessListener.Dial()
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.inProcessConn = conn
+
+	return conn, nil
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 const (
@@ -10,8 +14,9 @@ const (
 )
 
 type RPCListenConfig struct {
-	IP   string `json:"ip" yaml:"ip"`
-	Port uint16 `json:"port" yaml:"port"`
+	IP        string `json:"ip" yaml:"ip"`
+	Port      uint16 `json:"port" yaml:"port"`
+	ReusePort bool   `json:"reusePort" yaml:"reusePort"`
 }
 
 func (config RPCListenConfig) GetIP() string {
@@ -34,6 +39,11 @@ func (config RPCListenConfig) GetPort() uint16 {
 	return config.Port
 }
 
+func (config RPCListenConfig) IsReusePort() bool {
+
+	return config.ReusePort
+}
+
 type RPCListenOption func(config *RPCListenConfig)
 
 func RPCListenConfigIP(ip string) RPCListenOption {
@@ -50,6 +60,13 @@ func RPCListenConfigPort(port uint16) RPCListenOption {
 	}
 }
 
+func RPCListenConfigReusePort(reusePort bool) RPCListenOption {
+
+	return func(config *RPCListenConfig) {
+		config.ReusePort = reusePort
+	}
+}
+
 func NewRPCListenConfig(options ...RPCListenOption) *RPCListenConfig {
 
 	config := &RPCListenConfig{}
@@ -63,7 +80,40 @@ func NewRPCListenConfig(options ...RPCListenOption) *RPCListenConfig {
 }
 
 type RPCConfig struct {
-	ListenConfig *RPCListenConfig
+	ListenConfig         *RPCListenConfig
+	UnaryInterceptors    []grpc.UnaryServerInterceptor
+	StreamInterceptors   []grpc.StreamServerInterceptor
+	ReadWriteTimeout     time.Duration
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+	MaxConcurrentStreams uint32
+	Keepalive            keepalive.ServerParameters
+	KeepaliveEnforcement keepalive.EnforcementPolicy
+	Reflection           bool
+	ServerOptions        []grpc.ServerOption
+}
+
+// GetServerOptions returns the options of grpc server, the zero values are not applied, so grpc defaults are used.
+func (config *RPCConfig) GetServerOptions() []grpc.ServerOption {
+
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(config.Keepalive),
+		grpc.KeepaliveEnforcementPolicy(config.KeepaliveEnforcement),
+	}
+
+	if config.MaxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(config.MaxRecvMsgSize))
+	}
+
+	if config.MaxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(config.MaxSendMsgSize))
+	}
+
+	if config.MaxConcurrentStreams > 0 {
+		options = append(options, grpc.MaxConcurrentStreams(config.MaxConcurrentStreams))
+	}
+
+	return append(options, config.ServerOptions...)
 }
 
 func (config *RPCConfig) String() string {
@@ -92,3 +142,71 @@ func RPCConfigListenConfig(ListenConfig *RPCListenConfig) RPCConfigOption {
 		config.ListenConfig = ListenConfig
 	}
 }
+
+func RPCConfigUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.UnaryInterceptors = append(config.UnaryInterceptors, interceptors...)
+	}
+}
+
+func RPCConfigStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.StreamInterceptors = append(config.StreamInterceptors, interceptors...)
+	}
+}
+
+// RPCConfigReadWriteTimeout sets deadline of unary calls which have no deadline set by client.
+func RPCConfigReadWriteTimeout(timeout time.Duration) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.ReadWriteTimeout = timeout
+	}
+}
+
+func RPCConfigMaxMsgSize(recv, send int) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.MaxRecvMsgSize = recv
+		config.MaxSendMsgSize = send
+	}
+}
+
+func RPCConfigMaxConcurrentStreams(streams uint32) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.MaxConcurrentStreams = streams
+	}
+}
+
+func RPCConfigKeepalive(parameters keepalive.ServerParameters, enforcement keepalive.EnforcementPolicy) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.Keepalive = parameters
+		config.KeepaliveEnforcement = enforcement
+	}
+}
+
+// RPCConfigReflection registers reflection service, so the services can be called by grpcurl.
+func RPCConfigReflection(enable bool) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.Reflection = enable
+	}
+}
+
+func RPCConfigServerOptions(options ...grpc.ServerOption) RPCConfigOption {
+
+	return func(config *RPCConfig) {
+
+		config.ServerOptions = append(config.ServerOptions, options...)
+	}
+}
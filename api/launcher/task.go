@@ -0,0 +1,187 @@
+package launcher
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/launcher/cmd"
+)
+
+const taskCommandName = "task"
+
+// TaskFunc is the body of a one-shot task, ctx will be canceled when the process receives a stop signal.
+type TaskFunc func(ctx context.Context, app *Application, args []string) error
+
+// TaskFlagsFunc registers the flags of task, e.g. flags.BoolVar(&dryRun, "dry-run", false, "...")
+type TaskFlagsFunc func(flags *pflag.FlagSet)
+
+// Task runs as a sub command of "task": ./service task <name> [flags] [args]
+// It shares configuration, logger, mysql, redis and rpc client connections with the application, but not start gin or rpc service.
+type Task struct {
+	Name             string
+	ShortDescription string
+	LongDescription  string
+	Flags            TaskFlagsFunc
+	Run              TaskFunc
+}
+
+// TaskExitCoder can be implemented by errors returned from TaskFunc to specify the process exit code.
+type TaskExitCoder interface {
+	ExitCode() int
+}
+
+type TaskOption func(task *Task)
+
+func TaskShortDescription(short string) TaskOption {
+
+	return func(task *Task) {
+		task.ShortDescription = short
+	}
+}
+
+func TaskLongDescription(long string) TaskOption {
+
+	return func(task *Task) {
+		task.LongDescription = long
+	}
+}
+
+func TaskFlags(flags TaskFlagsFunc) TaskOption {
+
+	return func(task *Task) {
+		task.Flags = flags
+	}
+}
+
+func NewTask(name string, run TaskFunc, options ...TaskOption) *Task {
+
+	task := &Task{
+		Name: name,
+		Run:  run,
+	}
+
+	for _, option := range options {
+
+		option(task)
+	}
+
+	return task
+}
+
+func AddApplicationTasks(tasks ...*Task) ApplicationOption {
+
+	return func(app *Application) {
+
+		for _, task := range tasks {
+			if task != nil && task.Run != nil {
+				app.tasks = append(app.tasks, task)
+			}
+		}
+	}
+}
+
+func (app *Application) initTaskCommand() {
+
+	if len(app.tasks) == 0 {
+		return
+	}
+
+	app.logger.Debug("init task command")
+
+	taskCommand := &cobra.Command{
+		Use:   taskCommandName,
+		Short: "Run one-shot task",
+		Long:  "Run one-shot task with the same configuration and connections of service",
+	}
+
+	for _, task := range app.tasks {
+
+		taskCommand.AddCommand(app.newTaskCommand(task))
+	}
+
+	cmd.InitRootCommand(cmd.AddSubCommand(taskCommand))
+
+	app.logger.Debug("init task command completed")
+}
+
+func (app *Application) newTaskCommand(task *Task) *cobra.Command {
+
+	command := &cobra.Command{
+		Use:   task.Name,
+		Short: task.ShortDescription,
+		Long:  task.LongDescription,
+		Run: func(command *cobra.Command, args []string) {
+
+			app.loadConfig()
+
+			os.Exit(app.runTask(task, args))
+		},
+	}
+
+	if task.Flags != nil {
+		task.Flags(command.Flags())
+	}
+
+	return command
+}
+
+func (app *Application) runTask(task *Task, args []string) (exitCode int) {
+
+	app.initTask()
+
+	logger := app.logger.WithField("task", task.Name)
+	logger.Info("start to run task")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chanSignal := make(chan os.Signal, 1)
+	signal.Notify(chanSignal, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(chanSignal)
+
+	go func() {
+		select {
+		case sig := <-chanSignal:
+			logger.Infof("Received signal: %d, cancel task", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	defer app.releaseConnections()
+
+	err := task.Run(ctx, app, args)
+	if err != nil {
+
+		exitCode = 1
+		if coder, ok := err.(TaskExitCoder); ok && coder.ExitCode() != 0 {
+			exitCode = coder.ExitCode()
+		}
+
+		logger.WithError(err).WithField("exitCode", exitCode).Error("task failed")
+		return
+	}
+
+	logger.Info("task completed")
+	return 0
+}
+
+func (app *Application) initTask() {
+
+	app.logger.Debug("start to init task")
+	app.initRandomSeed()
+	app.initServiceId()
+	app.initLogger()
+	app.initRuntime()
+	app.initI18n()
+	app.initJWT()
+
+	app.initConnections()
+
+	app.logger.Debug("init task completed")
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/interceptor"
+)
+
+type Option func(config *Config)
+
+type Config struct {
+	target             string
+	timeout            time.Duration
+	retries            uint
+	retryBackoff       time.Duration
+	idempotentMethods  map[string]bool
+	methodTimeouts     map[string]time.Duration
+	connectTimeout     time.Duration
+	keepaliveTime      time.Duration
+	keepaliveTimeout   time.Duration
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+	dialOptions        []grpc.DialOption
+}
+
+var defaultConfig = Config{
+	timeout:          3 * time.Second,
+	retryBackoff:     100 * time.Millisecond,
+	connectTimeout:   5 * time.Second,
+	keepaliveTime:    5 * time.Minute,
+	keepaliveTimeout: 20 * time.Second,
+}
+
+func Target(target string) Option {
+	return func(config *Config) {
+		config.target = target
+	}
+}
+
+// Timeout is the default timeout of every call, it's not applied when the context has an earlier deadline.
+func Timeout(timeout time.Duration) Option {
+	return func(config *Config) {
+		config.timeout = timeout
+	}
+}
+
+// Retry retries the calls of IdempotentMethods failed with codes.Unavailable, the backoff is doubled after every retry.
+func Retry(retries uint, backoff time.Duration) Option {
+	return func(config *Config) {
+		config.retries = retries
+		if backoff > 0 {
+			config.retryBackoff = backoff
+		}
+	}
+}
+
+// IdempotentMethods marks the methods safe to retry, the methods are full name, e.g. /protos.ExampleController/Ping.
+// The other methods are not retried, because codes.Unavailable may be returned after the server received the request.
+func IdempotentMethods(methods ...string) Option {
+	return func(config *Config) {
+		if config.idempotentMethods == nil {
+			config.idempotentMethods = make(map[string]bool)
+		}
+		for _, method := range methods {
+			config.idempotentMethods[method] = true
+		}
+	}
+}
+
+// MethodTimeout overrides the default timeout for the method, the method is full name, e.g. /protos.ExampleController/Ping
+func MethodTimeout(method string, timeout time.Duration) Option {
+	return func(config *Config) {
+		if config.methodTimeouts == nil {
+			config.methodTimeouts = make(map[string]time.Duration)
+		}
+		config.methodTimeouts[method] = timeout
+	}
+}
+
+// ConnectTimeout is the maximum time of waiting for connection ready when connecting.
+func ConnectTimeout(timeout time.Duration) Option {
+	return func(config *Config) {
+		config.connectTimeout = timeout
+	}
+}
+
+// Keepalive pings server after the connection is idle for time, and closes the connection if no response within timeout.
+// The time should not be shorter than the minimum ping interval permitted by server, otherwise the connection is closed by server.
+func Keepalive(time, timeout time.Duration) Option {
+	return func(config *Config) {
+		config.keepaliveTime = time
+		config.keepaliveTimeout = timeout
+	}
+}
+
+// UnaryInterceptors appends interceptors after the standard interceptors.
+func UnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(config *Config) {
+		config.unaryInterceptors = append(config.unaryInterceptors, interceptors...)
+	}
+}
+
+// StreamInterceptors appends interceptors after the standard interceptors.
+func StreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(config *Config) {
+		config.streamInterceptors = append(config.streamInterceptors, interceptors...)
+	}
+}
+
+func DialOptions(options ...grpc.DialOption) Option {
+	return func(config *Config) {
+		config.dialOptions = append(config.dialOptions, options...)
+	}
+}
+
+func NewConfig(options ...Option) *Config {
+
+	config := defaultConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &config
+}
+
+func (config *Config) GetTarget() string {
+	return config.target
+}
+
+// GetDialOptions returns the standard dial options: insecure, keepalive,
+// and interceptors of request id, timeout, logging, metrics and retry.
+func (config *Config) GetDialOptions() []grpc.DialOption {
+
+	entry := logrus.NewEntry(logger).WithField("target", config.target)
+
+	unaryInterceptors := append([]grpc.UnaryClientInterceptor{
+		interceptor.UnaryClientRequestId(),
+		interceptor.UnaryClientTimeout(config.timeout, config.methodTimeouts),
+		interceptor.UnaryClientLogger(entry),
+		interceptor.UnaryClientMetrics(),
+		interceptor.UnaryClientRetry(config.retries, config.retryBackoff, config.idempotentMethods),
+	}, config.unaryInterceptors...)
+
+	streamInterceptors := append([]grpc.StreamClientInterceptor{
+		interceptor.StreamClientRequestId(),
+		interceptor.StreamClientLogger(entry),
+		interceptor.StreamClientMetrics(),
+	}, config.streamInterceptors...)
+
+	options := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    config.keepaliveTime,
+			Timeout: config.keepaliveTimeout,
+		}),
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(streamInterceptors...),
+	}
+
+	return append(options, config.dialOptions...)
+}
+
+type Connection struct {
+	*grpc.ClientConn
+	*Config
+}
+
+// Connect dials the target if not dialed, and waits until the connection is ready or connect timeout.
+// The connection is reconnected by grpc itself after it's broken.
+func (conn *Connection) Connect() error {
+
+	if err := conn.dial(); err != nil {
+		return err
+	}
+
+	return conn.waitForReady()
+}
+
+// dial returns immediately, the connection is established in background.
+func (conn *Connection) dial() error {
+
+	if conn.Config == nil {
+		return errorNotHaveConfig
+	}
+
+	if conn.ClientConn != nil && conn.ClientConn.GetState() != connectivity.Shutdown {
+		return nil
+	}
+
+	clientConn, err := grpc.Dial(conn.Config.target, conn.Config.GetDialOptions()...)
+	if err != nil {
+		return err
+	}
+
+	conn.ClientConn = clientConn
+
+	return nil
+}
+
+func (conn *Connection) waitForReady() error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), conn.Config.connectTimeout)
+	defer cancel()
+
+	for {
+
+		state := conn.ClientConn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+
+		if !conn.ClientConn.WaitForStateChange(ctx, state) {
+			logger.WithField("target", conn.Config.target).
+				WithField("state", conn.ClientConn.GetState().String()).
+				Error("wait for connection ready timeout")
+			return errorNotReady
+		}
+	}
+}
+
+// TryConnect checks the connection is not broken, the idle connection is treated as alive,
+// because grpc connects it when the next call happens.
+func (conn *Connection) TryConnect() error {
+
+	if conn.ClientConn == nil {
+		return errorNotHaveConnection
+	}
+
+	switch conn.ClientConn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return errorNotReady
+	}
+
+	return nil
+}
+
+// Close closes the connection, the calls in progress are canceled,
+// so it should be called after the services stopped.
+func (conn *Connection) Close() error {
+
+	if conn.ClientConn == nil {
+		return nil
+	}
+
+	return conn.ClientConn.Close()
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"errors"
+)
+
+var errorNotHaveConfig = errors.New("not set configuration")
+var errorNotHaveConnection = errors.New("not have connection")
+var errorNotReady = errors.New("connection not ready")
+
+func IsNotHaveConfigError(err error) bool {
+	return err == errorNotHaveConfig
+}
+
+func IsNotHaveConnectionError(err error) bool {
+	return err == errorNotHaveConnection
+}
+
+func IsNotReadyError(err error) bool {
+	return err == errorNotReady
+}
@@ -0,0 +1,13 @@
+package client
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	logger = logrus.New()
+)
+
+func SetLogger(log *logrus.Logger) {
+	logger = log
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"sync"
+)
+
+var pool = make(map[string]*Connection)
+var poolLock sync.RWMutex
+
+// Connect dials the target and waits for the connection ready,
+// the pool is not locked while waiting, so the connection can be got by others at the same time.
+func Connect(key string, config *Config) (err error) {
+
+	conn, err := dial(key, config)
+	if err != nil {
+		return err
+	}
+
+	return conn.waitForReady()
+}
+
+func dial(key string, config *Config) (*Connection, error) {
+
+	poolLock.Lock()
+	defer poolLock.Unlock()
+
+	conn, exist := pool[key]
+	if !exist {
+		conn = &Connection{
+			ClientConn: nil,
+			Config:     config,
+		}
+		pool[key] = conn
+	}
+
+	return conn, conn.dial()
+}
+
+func Disconnect(key string) (err error) {
+
+	poolLock.Lock()
+	defer poolLock.Unlock()
+
+	conn, exist := pool[key]
+	if !exist {
+		return nil
+	}
+
+	delete(pool, key)
+
+	return conn.Close()
+}
+
+// Ping checks the connection is alive, it returns error if the connection not exist or broken.
+func Ping(key string) error {
+
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
+	conn, exist := pool[key]
+	if !exist {
+		return errorNotHaveConnection
+	}
+
+	return conn.TryConnect()
+}
+
+func Get(key string) (*Connection, error) {
+
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
+	conn, exist := pool[key]
+	if !exist || conn.ClientConn == nil {
+		return nil, errorNotHaveConnection
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func startServer(t *testing.T) (string, func()) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	return listener.Addr().String(), server.Stop
+}
+
+func TestConnect(t *testing.T) {
+
+	address, stop := startServer(t)
+	defer stop()
+
+	key := "test"
+
+	_, err := Get(key)
+	assert.True(t, IsNotHaveConnectionError(err))
+	assert.True(t, IsNotHaveConnectionError(Ping(key)))
+
+	assert.Nil(t, Connect(key, NewConfig(Target(address), Timeout(time.Second))))
+	assert.Nil(t, Ping(key))
+
+	conn, err := Get(key)
+	if assert.Nil(t, err) {
+		reply, err := grpc_health_v1.NewHealthClient(conn.ClientConn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, reply.GetStatus())
+	}
+
+	assert.Nil(t, Disconnect(key))
+	_, err = Get(key)
+	assert.True(t, IsNotHaveConnectionError(err))
+}
+
+func TestConnect_NotReady(t *testing.T) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	key := "notReady"
+	defer Disconnect(key)
+
+	err = Connect(key, NewConfig(Target(address), ConnectTimeout(100*time.Millisecond)))
+	assert.True(t, IsNotReadyError(err))
+}
@@ -0,0 +1,343 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/i18n"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/interceptor"
+)
+
+const (
+	DefaultPathPrefix = "/rpc"
+	// DefaultMaxBodySize is the default max receive message size of grpc
+	DefaultMaxBodySize = 4 << 20
+
+	paramService = "service"
+	paramMethod  = "method"
+)
+
+var DefaultForwardHeaders = []string{"Authorization"}
+
+var errorBodyTooLarge = stderrors.New("request body too large")
+
+type Config struct {
+	PathPrefix         string
+	ForwardHeaders     []string // forwarded to rpc service as metadata, the keys are lower case
+	AllowUnknownFields bool
+	EmitDefaults       bool  // output the fields with zero values
+	MaxBodySize        int64 // the request with larger body is responded 413
+}
+
+type Option func(config *Config)
+
+func PathPrefix(prefix string) Option {
+	return func(config *Config) {
+		if prefix != "" {
+			config.PathPrefix = prefix
+		}
+	}
+}
+
+func ForwardHeaders(headers ...string) Option {
+	return func(config *Config) {
+		config.ForwardHeaders = append(config.ForwardHeaders, headers...)
+	}
+}
+
+func AllowUnknownFields(allow bool) Option {
+	return func(config *Config) {
+		config.AllowUnknownFields = allow
+	}
+}
+
+func MaxBodySize(size int64) Option {
+	return func(config *Config) {
+		if size > 0 {
+			config.MaxBodySize = size
+		}
+	}
+}
+
+func EmitDefaults(emit bool) Option {
+	return func(config *Config) {
+		config.EmitDefaults = emit
+	}
+}
+
+// Gateway exposes the unary methods of services registered on grpc server as POST /rpc/{service}/{method},
+// e.g. POST /rpc/protos.ExampleController/Ping, the json body is converted to request message by jsonpb,
+// and the reply is wrapped in response.ResponseData.
+// The call goes through the connection given, usually the in-process connection of rpc service,
+// so the server interceptors are applied as well as the calls from network.
+type Gateway struct {
+	server  *grpc.Server
+	conn    *grpc.ClientConn
+	config  Config
+	lock    sync.RWMutex
+	methods map[string]*method
+}
+
+type method struct {
+	fullMethod string
+	input      reflect.Type
+	output     reflect.Type
+}
+
+func NewGateway(server *grpc.Server, conn *grpc.ClientConn, options ...Option) *Gateway {
+
+	config := Config{
+		PathPrefix:     DefaultPathPrefix,
+		ForwardHeaders: append([]string{}, DefaultForwardHeaders...),
+		EmitDefaults:   true,
+		MaxBodySize:    DefaultMaxBodySize,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &Gateway{
+		server:  server,
+		conn:    conn,
+		config:  config,
+		methods: make(map[string]*method),
+	}
+}
+
+// Mount registers the route of gateway, the services can be registered on grpc server after mounted.
+func (gateway *Gateway) Mount(router gin.IRouter) {
+
+	path := fmt.Sprintf("%s/:%s/:%s", strings.TrimRight(gateway.config.PathPrefix, "/"), paramService, paramMethod)
+	router.POST(path, gateway.Handle)
+}
+
+func (gateway *Gateway) Handle(c *gin.Context) {
+
+	m, err := gateway.getMethod(c.Param(paramService), c.Param(paramMethod))
+	if err != nil {
+		response.Error(c, errors.RequestPathError.WithMessage(err.Error()))
+		return
+	}
+
+	input, err := gateway.decode(c, m)
+	if err == errorBodyTooLarge {
+		response.Error(c, errors.RequestEntityTooLarge)
+		return
+	}
+
+	if err != nil {
+		response.Error(c, errors.RequestJSONDecodeFailed.WithMessage(err.Error()))
+		return
+	}
+
+	output := reflect.New(m.output.Elem()).Interface().(proto.Message)
+
+	err = gateway.conn.Invoke(gateway.newContext(c), m.fullMethod, input, output)
+	if err != nil {
+		st := status.Convert(err)
+		httpStatus := interceptor.CodeToHTTPStatus(st.Code())
+
+		errorCode, ok := interceptor.GetErrorCode(st)
+		if !ok {
+			errorCode = httpStatus * 1000
+		}
+
+		response.E(c, httpStatus, errorCode, st.Message())
+		return
+	}
+
+	marshaler := &jsonpb.Marshaler{EmitDefaults: gateway.config.EmitDefaults}
+	data, err := marshaler.MarshalToString(output)
+	if err != nil {
+		response.InternalErr(c, errors.CodeServerInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ResponseData{
+		Data: json.RawMessage(data),
+	})
+}
+
+func (gateway *Gateway) decode(c *gin.Context, m *method) (proto.Message, error) {
+
+	input := reflect.New(m.input.Elem()).Interface().(proto.Message)
+
+	// read one more byte than the limit to know whether the body is larger
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, gateway.config.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > gateway.config.MaxBodySize {
+		return nil, errorBodyTooLarge
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return input, nil
+	}
+
+	unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: gateway.config.AllowUnknownFields}
+	if err = unmarshaler.Unmarshal(bytes.NewReader(body), input); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// newContext carries request id, deadline of request, language and forwarded headers to rpc service
+func (gateway *Gateway) newContext(c *gin.Context) context.Context {
+
+	ctx := requestid.GetRPCContext(c)
+
+	pairs := []string{i18n.MetadataKey, i18n.GetLanguage(c)}
+	for _, header := range gateway.config.ForwardHeaders {
+		if value := c.GetHeader(header); value != "" {
+			pairs = append(pairs, strings.ToLower(header), value)
+		}
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func (gateway *Gateway) getMethod(serviceName, methodName string) (*method, error) {
+
+	fullMethod := fmt.Sprintf("/%s/%s", serviceName, methodName)
+
+	gateway.lock.RLock()
+	m, exist := gateway.methods[fullMethod]
+	gateway.lock.RUnlock()
+
+	if exist {
+		return m, nil
+	}
+
+	m, err := gateway.resolveMethod(serviceName, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway.lock.Lock()
+	gateway.methods[fullMethod] = m
+	gateway.lock.Unlock()
+
+	return m, nil
+}
+
+// resolveMethod finds the message types of method from the file descriptor registered by generated code
+func (gateway *Gateway) resolveMethod(serviceName, methodName string) (*method, error) {
+
+	info, exist := gateway.server.GetServiceInfo()[serviceName]
+	if !exist {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+
+	found := false
+	for _, methodInfo := range info.Methods {
+		if methodInfo.Name != methodName {
+			continue
+		}
+		if methodInfo.IsClientStream || methodInfo.IsServerStream {
+			return nil, fmt.Errorf("streaming method %s is not supported", methodName)
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+
+	fileName, ok := info.Metadata.(string)
+	if !ok {
+		return nil, fmt.Errorf("file descriptor of service %s not found", serviceName)
+	}
+
+	file, err := loadFileDescriptor(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range file.GetService() {
+
+		if qualifiedName(file.GetPackage(), service.GetName()) != serviceName {
+			continue
+		}
+
+		for _, methodDescriptor := range service.GetMethod() {
+
+			if methodDescriptor.GetName() != methodName {
+				continue
+			}
+
+			input := proto.MessageType(strings.TrimPrefix(methodDescriptor.GetInputType(), "."))
+			output := proto.MessageType(strings.TrimPrefix(methodDescriptor.GetOutputType(), "."))
+			if input == nil || output == nil {
+				return nil, fmt.Errorf("message types of method %s not registered", methodName)
+			}
+
+			return &method{
+				fullMethod: fmt.Sprintf("/%s/%s", serviceName, methodName),
+				input:      input,
+				output:     output,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("method %s not found in file descriptor %s", methodName, fileName)
+}
+
+func qualifiedName(packageName, name string) string {
+
+	if packageName == "" {
+		return name
+	}
+
+	return packageName + "." + name
+}
+
+func loadFileDescriptor(fileName string) (*descriptor.FileDescriptorProto, error) {
+
+	compressed := proto.FileDescriptor(fileName)
+	if compressed == nil {
+		return nil, fmt.Errorf("file descriptor %s not registered", fileName)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &descriptor.FileDescriptorProto{}
+	if err = proto.Unmarshal(raw, file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+func prepareGateway(t *testing.T, interceptor grpc.UnaryServerInterceptor, options ...Option) (*gin.Engine, func()) {
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	NewGateway(server, conn, options...).Mount(engine)
+
+	return engine, func() {
+		_ = conn.Close()
+		server.Stop()
+	}
+}
+
+func TestGateway_Handle(t *testing.T) {
+
+	var gotAuthorization []string
+	engine, stop := prepareGateway(t, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		gotAuthorization = md.Get("authorization")
+		return handler(ctx, req)
+	})
+	defer stop()
+
+	tests := []struct {
+		path       string
+		body       string
+		wantStatus int
+		wantCode   int
+		wantData   string
+	}{
+		{
+			path:       "/rpc/grpc.health.v1.Health/Check",
+			body:       `{"service": ""}`,
+			wantStatus: http.StatusOK,
+			wantData:   `{"status":"SERVING"}`,
+		},
+		{
+			path:       "/rpc/grpc.health.v1.Health/Check",
+			body:       ``,
+			wantStatus: http.StatusOK,
+			wantData:   `{"status":"SERVING"}`,
+		},
+		{
+			path:       "/rpc/grpc.health.v1.Health/Check",
+			body:       `{"service": "unknown"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   http.StatusNotFound * 1000,
+		},
+		{
+			path:       "/rpc/grpc.health.v1.Health/Check",
+			body:       `{"unknown": 1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.CodeRequestJSONDecodeFailed,
+		},
+		{
+			path:       "/rpc/grpc.health.v1.Health/Watch",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   errors.CodeRequestPathError,
+		},
+		{
+			path:       "/rpc/grpc.health.v1.Unknown/Check",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   errors.CodeRequestPathError,
+		},
+	}
+
+	for _, test := range tests {
+
+		req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		req.Header.Set("Authorization", "Bearer token")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		assert.Equal(t, test.wantStatus, recorder.Code, test.body)
+
+		result := struct {
+			Code int             `json:"code"`
+			Data json.RawMessage `json:"data"`
+		}{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &result), recorder.Body.String())
+		assert.Equal(t, test.wantCode, result.Code, recorder.Body.String())
+		if test.wantData != "" {
+			assert.JSONEq(t, test.wantData, string(result.Data))
+		}
+	}
+
+	assert.Equal(t, []string{"Bearer token"}, gotAuthorization)
+}
+
+func TestGateway_MaxBodySize(t *testing.T) {
+
+	engine, stop := prepareGateway(t, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}, MaxBodySize(16))
+	defer stop()
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantCode   int
+	}{
+		{body: `{"service": ""}`, wantStatus: http.StatusOK},
+		{body: `{"service":  ""}`, wantStatus: http.StatusOK},
+		{body: `{"service":   ""}`, wantStatus: http.StatusRequestEntityTooLarge, wantCode: errors.CodeRequestEntityTooLarge},
+		{body: `{"service": "too large body"}`, wantStatus: http.StatusRequestEntityTooLarge, wantCode: errors.CodeRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+
+		req := httptest.NewRequest(http.MethodPost, "/rpc/grpc.health.v1.Health/Check", strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		assert.Equal(t, test.wantStatus, recorder.Code, recorder.Body.String())
+
+		result := struct {
+			Code int `json:"code"`
+		}{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+		assert.Equal(t, test.wantCode, result.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,258 @@
+package interceptor
+
+import (
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+// TokenMetadataKey is the metadata of token, the Authorization header is forwarded as it by gateway.
+const TokenMetadataKey = "authorization"
+
+const bearerPrefix = "bearer "
+
+type AuthenticationConfig struct {
+	Allowlist         []string // full methods, e.g. /protos.UserController/Login, or service prefixes, e.g. /protos.PublicController/
+	RevocationChecker auth.RevocationChecker
+	SessionChecker    auth.SessionChecker
+}
+
+type AuthenticationOption func(config *AuthenticationConfig)
+
+// AuthenticationAllowlist sets the public methods, they are not authenticated but the valid token is still resolved.
+func AuthenticationAllowlist(methods ...string) AuthenticationOption {
+	return func(config *AuthenticationConfig) {
+		config.Allowlist = append(config.Allowlist, methods...)
+	}
+}
+
+// AuthenticationRevocation rejects the revoked tokens, e.g. auth.TokenManager.
+func AuthenticationRevocation(checker auth.RevocationChecker) AuthenticationOption {
+	return func(config *AuthenticationConfig) {
+		config.RevocationChecker = checker
+	}
+}
+
+// AuthenticationSessions rejects the tokens of superseded sessions with errors.LoggedInElsewhere, e.g. auth.SessionManager.
+func AuthenticationSessions(checker auth.SessionChecker) AuthenticationOption {
+	return func(config *AuthenticationConfig) {
+		config.SessionChecker = checker
+	}
+}
+
+type tokenParser func(tokenString string, log *logrus.Entry) (jwt.MapClaims, error)
+
+type authenticator struct {
+	config AuthenticationConfig
+	parse  tokenParser
+}
+
+// UnaryServerUserJwtAuthentication verifies the token of authorization metadata by the secret like auth.ResolveJWTToken,
+// the user can be got by auth.GetUserIdFromContext, auth.GetUserInfoFromContext and auth.FromContext.
+func UnaryServerUserJwtAuthentication(tokenKey string, options ...AuthenticationOption) grpc.UnaryServerInterceptor {
+	return newAuthenticator(secretParser(tokenKey), options...).unary
+}
+
+func StreamServerUserJwtAuthentication(tokenKey string, options ...AuthenticationOption) grpc.StreamServerInterceptor {
+	return newAuthenticator(secretParser(tokenKey), options...).stream
+}
+
+// UnaryServerJWTAuthentication verifies tokens by the keys of j, e.g. RS256 keys with rotation.
+func UnaryServerJWTAuthentication(j *auth.JWT, options ...AuthenticationOption) grpc.UnaryServerInterceptor {
+	return newAuthenticator(j.Parse, options...).unary
+}
+
+func StreamServerJWTAuthentication(j *auth.JWT, options ...AuthenticationOption) grpc.StreamServerInterceptor {
+	return newAuthenticator(j.Parse, options...).stream
+}
+
+func secretParser(tokenKey string) tokenParser {
+	return func(tokenString string, log *logrus.Entry) (jwt.MapClaims, error) {
+		return auth.ParseJWTToken(tokenString, tokenKey, log)
+	}
+}
+
+func newAuthenticator(parse tokenParser, options ...AuthenticationOption) *authenticator {
+
+	config := AuthenticationConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &authenticator{config: config, parse: parse}
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+
+	public := a.isPublic(fullMethod)
+
+	token := getTokenFromMetadata(ctx)
+	if token == "" {
+		if public {
+			return ctx, nil
+		}
+		return ctx, ToLocalizedStatus(ctx, errors.RequestTokenInvalid).Err()
+	}
+
+	log := logrus.WithField("reqId", requestid.GetRequestIdFromRPCContext(ctx)).WithField("method", fullMethod)
+
+	authenticated, err := a.resolve(ctx, token, log)
+	if err != nil {
+		if public {
+			return ctx, nil
+		}
+		return ctx, ToLocalizedStatus(ctx, err).Err()
+	}
+
+	return authenticated, nil
+}
+
+// resolve returns the context carries the user, the error is an errors.Error
+func (a *authenticator) resolve(ctx context.Context, token string, log *logrus.Entry) (context.Context, error) {
+
+	claims, err := a.parse(token, log)
+	if err != nil {
+		if auth.IsTokenExpired(err) {
+			return ctx, errors.RequestTokenExpired
+		}
+		return ctx, errors.RequestTokenInvalid
+	}
+
+	if a.config.RevocationChecker != nil {
+
+		revoked, err := a.config.RevocationChecker.IsRevoked(ctx, claims)
+		if err != nil {
+			log.WithError(err).Error("check token revocation failed")
+			return ctx, errors.ServiceUnavailable
+		}
+
+		if revoked {
+			return ctx, errors.RequestTokenRevoked
+		}
+	}
+
+	if a.config.SessionChecker != nil {
+
+		if err = a.config.SessionChecker.CheckSession(ctx, claims); err != nil {
+			switch {
+			case auth.IsSessionSuperseded(err):
+				return ctx, errors.LoggedInElsewhere
+			case auth.IsTokenRevoked(err):
+				return ctx, errors.RequestTokenRevoked
+			case auth.IsTokenInvalid(err):
+				return ctx, errors.RequestTokenInvalid
+			}
+
+			log.WithError(err).Error("check token session failed")
+			return ctx, errors.ServiceUnavailable
+		}
+	}
+
+	_, info, err := auth.ResolveClaims(claims, log)
+	if err != nil {
+		return ctx, errors.RequestTokenInvalid
+	}
+
+	userClaims, err := auth.DecodeClaims(claims)
+	if err != nil {
+		log.WithError(err).Warn("decode user claims failed")
+		return ctx, errors.RequestTokenInvalid
+	}
+
+	ctx = auth.NewContext(ctx, userClaims)
+	ctx = auth.NewInfoContext(ctx, info)
+	ctx = auth.NewTokenContext(ctx, token)
+
+	return ctx, nil
+}
+
+func (a *authenticator) isPublic(fullMethod string) bool {
+
+	for _, method := range a.config.Allowlist {
+		if method == fullMethod || (strings.HasSuffix(method, "/") && strings.HasPrefix(fullMethod, method)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTokenFromMetadata returns the token without Bearer prefix
+func getTokenFromMetadata(ctx context.Context) string {
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(TokenMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	token := strings.TrimSpace(values[0])
+	if len(token) > len(bearerPrefix) && strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+		token = strings.TrimSpace(token[len(bearerPrefix):])
+	}
+
+	return token
+}
+
+// ForwardTokenCredentials sends the token of caller to the downstream services, e.g.
+//
+//	client.DialOptions(grpc.WithPerRPCCredentials(interceptor.ForwardTokenCredentials()))
+//
+// the token is got by auth.GetTokenFromContext, or from the incoming metadata if the context is not authenticated.
+func ForwardTokenCredentials() credentials.PerRPCCredentials {
+	return forwardTokenCredentials{}
+}
+
+type forwardTokenCredentials struct{}
+
+func (forwardTokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+
+	token := auth.GetTokenFromContext(ctx)
+	if token == "" {
+		token = getTokenFromMetadata(ctx)
+	}
+
+	if token == "" {
+		return nil, nil
+	}
+
+	return map[string]string{TokenMetadataKey: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity is false, because the services are called in internal network without tls.
+func (forwardTokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,91 @@
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+func TestUnaryServerUserJwtAuthentication(t *testing.T) {
+
+	token, _, err := auth.NewUserJwtToken(1, map[string]interface{}{"name": "wanxin"}, "secret")
+	assert.NoError(t, err)
+
+	j, err := auth.NewJWT(auth.JWTKeys(auth.NewHMACKey("", []byte("secret"))), auth.JWTLifetime(-time.Hour))
+	assert.NoError(t, err)
+	expired, _, err := j.Issue(1, nil)
+	assert.NoError(t, err)
+
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(TokenMetadataKey, token))
+	}
+
+	tests := []struct {
+		ctx       context.Context
+		method    string
+		want      codes.Code
+		errorCode int
+		userId    uint64
+	}{
+		{ctx: withToken("Bearer " + token), method: "/protos.UserController/Get", want: codes.OK, userId: 1},
+		{ctx: withToken(token), method: "/protos.UserController/Get", want: codes.OK, userId: 1},
+		{ctx: context.Background(), method: "/protos.UserController/Get", want: codes.Unauthenticated, errorCode: errors.CodeRequestTokenInvalid},
+		{ctx: withToken("Bearer invalid"), method: "/protos.UserController/Get", want: codes.Unauthenticated, errorCode: errors.CodeRequestTokenInvalid},
+		{ctx: withToken("Bearer " + expired), method: "/protos.UserController/Get", want: codes.Unauthenticated, errorCode: errors.CodeRequestTokenExpired},
+		{ctx: context.Background(), method: "/protos.UserController/Login", want: codes.OK},
+		{ctx: withToken("Bearer invalid"), method: "/protos.PublicController/Get", want: codes.OK},
+		{ctx: withToken("Bearer " + token), method: "/protos.PublicController/Get", want: codes.OK, userId: 1},
+	}
+
+	interceptor := UnaryServerUserJwtAuthentication("secret",
+		AuthenticationAllowlist("/protos.UserController/Login", "/protos.PublicController/"))
+
+	for index, test := range tests {
+
+		var userId uint64
+		_, err := interceptor(test.ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			userId = auth.GetUserIdFromContext(ctx)
+			if userId > 0 {
+				assert.Equal(t, "wanxin", auth.GetUserInfoFromContext(ctx)["name"], index)
+				assert.NotEmpty(t, auth.GetTokenFromContext(ctx), index)
+			}
+			return nil, nil
+		})
+
+		assert.Equal(t, test.want, status.Code(err), index)
+		assert.Equal(t, test.userId, userId, index)
+
+		if test.errorCode > 0 {
+			errorCode, ok := GetErrorCode(status.Convert(err))
+			assert.True(t, ok, index)
+			assert.Equal(t, test.errorCode, errorCode, index)
+		}
+	}
+}
+
+func TestForwardTokenCredentials(t *testing.T) {
+
+	tests := []struct {
+		ctx  context.Context
+		want map[string]string
+	}{
+		{ctx: auth.NewTokenContext(context.Background(), "abc"), want: map[string]string{TokenMetadataKey: "Bearer abc"}},
+		{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(TokenMetadataKey, "Bearer def")), want: map[string]string{TokenMetadataKey: "Bearer def"}},
+		{ctx: context.Background(), want: nil},
+	}
+
+	for _, test := range tests {
+		actual, err := ForwardTokenCredentials().GetRequestMetadata(test.ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, actual)
+	}
+}
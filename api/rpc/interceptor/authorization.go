@@ -0,0 +1,70 @@
+package interceptor
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+// UnaryServerAuthorization checks the requirements of methods by the claims put in context by authentication interceptor,
+// the key of requirements is full method, e.g. /protos.OrderController/Create, or service prefix, e.g. /protos.OrderController/,
+// the methods not listed are not checked.
+func UnaryServerAuthorization(policy *auth.Policy, requirements map[string]auth.Requirement) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		if err := authorize(ctx, policy, requirements, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerAuthorization(policy *auth.Policy, requirements map[string]auth.Requirement) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		if err := authorize(ss.Context(), policy, requirements, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, policy *auth.Policy, requirements map[string]auth.Requirement, fullMethod string) error {
+
+	requirement, ok := findRequirement(requirements, fullMethod)
+	if !ok {
+		return nil
+	}
+
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return ToLocalizedStatus(ctx, errors.RequestTokenInvalid).Err()
+	}
+
+	if !policy.Authorize(claims, requirement) {
+		return ToLocalizedStatus(ctx, errors.Forbidden).Err()
+	}
+
+	return nil
+}
+
+// findRequirement prefers the requirement of method to the service
+func findRequirement(requirements map[string]auth.Requirement, fullMethod string) (auth.Requirement, bool) {
+
+	if requirement, ok := requirements[fullMethod]; ok {
+		return requirement, true
+	}
+
+	if index := strings.LastIndex(fullMethod, "/"); index > 0 {
+		requirement, ok := requirements[fullMethod[:index+1]]
+		return requirement, ok
+	}
+
+	return auth.Requirement{}, false
+}
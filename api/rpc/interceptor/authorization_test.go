@@ -0,0 +1,45 @@
+package interceptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/auth"
+)
+
+func TestUnaryServerAuthorization(t *testing.T) {
+
+	policy := auth.NewPolicy(auth.PolicyRoles(map[string][]string{"operator": {"order:*"}}))
+	requirements := map[string]auth.Requirement{
+		"/protos.OrderController/":       {Permissions: []string{"order:read"}},
+		"/protos.OrderController/Delete": {Roles: []string{"admin"}},
+	}
+
+	operator := auth.NewContext(context.Background(), &auth.Claims{UserId: 1, Roles: []string{"operator"}})
+
+	tests := []struct {
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{ctx: operator, method: "/protos.OrderController/Get", want: codes.OK},
+		{ctx: operator, method: "/protos.OrderController/Delete", want: codes.PermissionDenied},
+		{ctx: context.Background(), method: "/protos.OrderController/Get", want: codes.Unauthenticated},
+		{ctx: context.Background(), method: "/protos.UserController/Get", want: codes.OK},
+	}
+
+	interceptor := UnaryServerAuthorization(policy, requirements)
+	for _, test := range tests {
+
+		_, err := interceptor(test.ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+
+		assert.Equal(t, test.want, status.Code(err), test.method)
+	}
+}
@@ -0,0 +1,30 @@
+package interceptor
+
+import (
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// DefaultUnaryServerInterceptors returns the standard chain of rpc service, in order:
+// request id, access log, panic recovery, error translation and request validation.
+func DefaultUnaryServerInterceptors(logger *logrus.Entry) []grpc.UnaryServerInterceptor {
+
+	return []grpc.UnaryServerInterceptor{
+		UnaryServerRequestId(),
+		UnaryServerLogger(logger),
+		UnaryServerRecovery(logger),
+		UnaryServerErrorTranslator(),
+		UnaryServerValidator(),
+	}
+}
+
+func DefaultStreamServerInterceptors(logger *logrus.Entry) []grpc.StreamServerInterceptor {
+
+	return []grpc.StreamServerInterceptor{
+		StreamServerRequestId(),
+		StreamServerLogger(logger),
+		StreamServerRecovery(logger),
+		StreamServerErrorTranslator(),
+		StreamServerValidator(),
+	}
+}
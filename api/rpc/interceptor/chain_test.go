@@ -0,0 +1,104 @@
+package interceptor
+
+import (
+	"bytes"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+)
+
+type testRequest struct {
+	Name string
+}
+
+func (r testRequest) Validate() error {
+	return validation.ValidateStruct(&r, validation.Field(&r.Name, validation.Required))
+}
+
+// chainUnary calls interceptors in the same order as grpc.ChainUnaryInterceptor
+func chainUnary(interceptors []grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) grpc.UnaryHandler {
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, next)
+		}
+	}
+
+	return handler
+}
+
+func TestDefaultUnaryServerInterceptors(t *testing.T) {
+
+	tests := []struct {
+		req          interface{}
+		handler      grpc.UnaryHandler
+		wantCode     codes.Code
+		wantErrCode  int
+		wantLogLevel string
+	}{
+		{
+			req: testRequest{Name: "name"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			},
+			wantCode:     codes.OK,
+			wantLogLevel: "level=info",
+		},
+		{
+			req: testRequest{},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				t.Fatal("handler should not be called when request invalid")
+				return nil, nil
+			},
+			wantCode:     codes.InvalidArgument,
+			wantErrCode:  errors.CodeRequestParamError,
+			wantLogLevel: "level=warning",
+		},
+		{
+			req: testRequest{Name: "name"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				panic("test panic")
+			},
+			wantCode:     codes.Internal,
+			wantErrCode:  errors.CodeServerInternalError,
+			wantLogLevel: "level=error",
+		},
+		{
+			req: testRequest{Name: "name"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, errors.RequestTokenInvalid
+			},
+			wantCode:     codes.Unauthenticated,
+			wantErrCode:  errors.CodeRequestTokenInvalid,
+			wantLogLevel: "level=warning",
+		},
+	}
+
+	for _, test := range tests {
+
+		output := &bytes.Buffer{}
+		logger := logrus.New()
+		logger.SetOutput(output)
+
+		_, err := chainUnary(DefaultUnaryServerInterceptors(logrus.NewEntry(logger)), test.handler)(context.Background(), test.req)
+
+		st := status.Convert(err)
+		assert.Equal(t, test.wantCode, st.Code())
+
+		errorCode, _ := GetErrorCode(st)
+		assert.Equal(t, test.wantErrCode, errorCode)
+
+		assert.Contains(t, output.String(), test.wantLogLevel)
+		assert.Contains(t, output.String(), "reqId=")
+		assert.Contains(t, output.String(), "method=/test.Service/Method")
+	}
+}
@@ -0,0 +1,216 @@
+package interceptor
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/utils/log"
+)
+
+const expvarClientMetricsName = "rpcClientCalls"
+
+// clientMetrics is published by expvar, e.g. {"/protos.ExampleController/Ping": {"calls": 3, "errors": 1, "latencyMicroseconds": 1500}}
+var clientMetrics = expvar.NewMap(expvarClientMetricsName)
+
+// clientMetricsLock guards the creation of method metrics, so the first concurrent calls don't overwrite each other
+var clientMetricsLock sync.Mutex
+
+// UnaryClientLogger logs every outgoing call, failed calls are logged as warning, others as debug.
+func UnaryClientLogger(logger *logrus.Entry) grpc.UnaryClientInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		logClientCall(ctx, logger, cc, method, start, err)
+
+		return err
+	}
+}
+
+// StreamClientLogger logs the establishment of stream, the messages of stream are not logged.
+func StreamClientLogger(logger *logrus.Entry) grpc.StreamClientInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		logClientCall(ctx, logger, cc, method, start, err)
+
+		return stream, err
+	}
+}
+
+func logClientCall(ctx context.Context, logger *logrus.Entry, cc *grpc.ClientConn, method string, start time.Time, err error) {
+
+	entry := log.WithRequestId(ctx, logger).WithFields(logrus.Fields{
+		"method":  method,
+		"code":    status.Code(err).String(),
+		"latency": time.Since(start),
+	})
+
+	if cc != nil {
+		entry = entry.WithField("target", cc.Target())
+	}
+
+	if err != nil {
+		entry.WithError(err).Warn("rpc call failed")
+		return
+	}
+
+	entry.Debug("rpc call")
+}
+
+// UnaryClientMetrics counts calls, errors and latency of every method, the metrics are published by expvar.
+func UnaryClientMetrics() grpc.UnaryClientInterceptor {
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		recordClientCall(method, start, err)
+
+		return err
+	}
+}
+
+func StreamClientMetrics() grpc.StreamClientInterceptor {
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		recordClientCall(method, start, err)
+
+		return stream, err
+	}
+}
+
+func recordClientCall(method string, start time.Time, err error) {
+
+	metrics := getClientMetrics(method)
+
+	metrics.Add("calls", 1)
+	metrics.Add("latencyMicroseconds", time.Since(start).Microseconds())
+	if err != nil {
+		metrics.Add("errors", 1)
+	}
+}
+
+func getClientMetrics(method string) *expvar.Map {
+
+	if metrics, ok := clientMetrics.Get(method).(*expvar.Map); ok {
+		return metrics
+	}
+
+	clientMetricsLock.Lock()
+	defer clientMetricsLock.Unlock()
+
+	metrics, ok := clientMetrics.Get(method).(*expvar.Map)
+	if !ok {
+		metrics = new(expvar.Map).Init()
+		clientMetrics.Set(method, metrics)
+	}
+
+	return metrics
+}
+
+// UnaryClientTimeout sets deadline of call when the context has no earlier deadline,
+// methodTimeouts are keyed by full method name, e.g. /protos.ExampleController/Ping, and override the default timeout.
+func UnaryClientTimeout(timeout time.Duration, methodTimeouts map[string]time.Duration) grpc.UnaryClientInterceptor {
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		ctx, cancel := withMethodTimeout(ctx, method, timeout, methodTimeouts)
+		defer cancel()
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func withMethodTimeout(ctx context.Context, method string, timeout time.Duration, methodTimeouts map[string]time.Duration) (context.Context, context.CancelFunc) {
+
+	if methodTimeout, exist := methodTimeouts[method]; exist {
+		timeout = methodTimeout
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// DefaultRetryCodes only contains codes.Unavailable. It's returned when the server is unreachable,
+// but also when the connection is broken after the server received the request,
+// so the request may have been processed, and only the idempotent methods can be retried.
+var DefaultRetryCodes = []codes.Code{codes.Unavailable}
+
+// UnaryClientRetry retries the call of idempotent methods failed with retry codes, the backoff is doubled after every retry,
+// the retries stop when context done. The idempotentMethods are keyed by full method name, e.g. /protos.ExampleController/Ping,
+// the other methods are never retried because the failed call may have been processed by server.
+func UnaryClientRetry(retries uint, backoff time.Duration, idempotentMethods map[string]bool, retryCodes ...codes.Code) grpc.UnaryClientInterceptor {
+
+	if len(retryCodes) == 0 {
+		retryCodes = DefaultRetryCodes
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if !idempotentMethods[method] {
+			return err
+		}
+
+		wait := backoff
+
+		for attempt := uint(1); attempt <= retries && err != nil && isRetryCode(status.Code(err), retryCodes); attempt++ {
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+
+			wait = wait * 2
+
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		return err
+	}
+}
+
+func isRetryCode(code codes.Code, retryCodes []codes.Code) bool {
+
+	for _, retryCode := range retryCodes {
+		if code == retryCode {
+			return true
+		}
+	}
+
+	return false
+}
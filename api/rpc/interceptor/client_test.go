@@ -0,0 +1,173 @@
+package interceptor
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryClientTimeout(t *testing.T) {
+
+	parent, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		ctx            context.Context
+		method         string
+		methodTimeouts map[string]time.Duration
+		wantDeadline   bool
+		wantMax        time.Duration
+	}{
+		{
+			ctx:          context.Background(),
+			method:       "/test.Service/Default",
+			wantDeadline: true,
+			wantMax:      time.Second,
+		},
+		{
+			ctx:            context.Background(),
+			method:         "/test.Service/Fast",
+			methodTimeouts: map[string]time.Duration{"/test.Service/Fast": 10 * time.Millisecond},
+			wantDeadline:   true,
+			wantMax:        10 * time.Millisecond,
+		},
+		{
+			ctx:          parent,
+			method:       "/test.Service/Default",
+			wantDeadline: true,
+			wantMax:      100 * time.Millisecond,
+		},
+		{
+			ctx:            context.Background(),
+			method:         "/test.Service/NoTimeout",
+			methodTimeouts: map[string]time.Duration{"/test.Service/NoTimeout": 0},
+			wantDeadline:   false,
+		},
+	}
+
+	for _, test := range tests {
+
+		err := UnaryClientTimeout(time.Second, test.methodTimeouts)(test.ctx, test.method, nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				deadline, ok := ctx.Deadline()
+				assert.Equal(t, test.wantDeadline, ok, test.method)
+				if ok {
+					assert.True(t, time.Until(deadline) <= test.wantMax, test.method)
+				}
+				return nil
+			})
+
+		assert.Nil(t, err)
+	}
+}
+
+func TestUnaryClientRetry(t *testing.T) {
+
+	tests := []struct {
+		retries     uint
+		method      string
+		errors      []error
+		wantCalls   int
+		wantErrCode codes.Code
+	}{
+		{
+			retries:     2,
+			method:      "/test.Service/Create",
+			errors:      []error{status.Error(codes.Unavailable, "")},
+			wantCalls:   1,
+			wantErrCode: codes.Unavailable,
+		},
+		{
+			retries:     2,
+			errors:      []error{status.Error(codes.Unavailable, ""), nil},
+			wantCalls:   2,
+			wantErrCode: codes.OK,
+		},
+		{
+			retries:     2,
+			errors:      []error{status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, "")},
+			wantCalls:   3,
+			wantErrCode: codes.Unavailable,
+		},
+		{
+			retries:     2,
+			errors:      []error{status.Error(codes.InvalidArgument, "")},
+			wantCalls:   1,
+			wantErrCode: codes.InvalidArgument,
+		},
+		{
+			retries:     0,
+			errors:      []error{status.Error(codes.Unavailable, "")},
+			wantCalls:   1,
+			wantErrCode: codes.Unavailable,
+		},
+	}
+
+	for _, test := range tests {
+
+		calls := 0
+		method := test.method
+		if method == "" {
+			method = "/test.Service/Get"
+		}
+
+		err := UnaryClientRetry(test.retries, time.Millisecond, map[string]bool{"/test.Service/Get": true})(context.Background(), method, nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				err := test.errors[calls]
+				calls++
+				return err
+			})
+
+		assert.Equal(t, test.wantCalls, calls)
+		assert.Equal(t, test.wantErrCode, status.Code(err))
+	}
+}
+
+func TestUnaryClientMetrics(t *testing.T) {
+
+	interceptor := UnaryClientMetrics()
+	invoker := func(err error) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return err
+		}
+	}
+
+	_ = interceptor(context.Background(), "/test.Service/Metrics", nil, nil, nil, invoker(nil))
+	_ = interceptor(context.Background(), "/test.Service/Metrics", nil, nil, nil, invoker(status.Error(codes.Internal, "")))
+
+	metrics, ok := clientMetrics.Get("/test.Service/Metrics").(*expvar.Map)
+	if assert.True(t, ok) {
+		assert.Equal(t, "2", metrics.Get("calls").String())
+		assert.Equal(t, "1", metrics.Get("errors").String())
+	}
+}
+
+func TestUnaryClientMetrics_Concurrent(t *testing.T) {
+
+	interceptor := UnaryClientMetrics()
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	var wait sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			_ = interceptor(context.Background(), "/test.Service/Concurrent", nil, nil, nil, invoker)
+		}()
+	}
+	wait.Wait()
+
+	metrics, ok := clientMetrics.Get("/test.Service/Concurrent").(*expvar.Map)
+	if assert.True(t, ok) {
+		assert.Equal(t, "50", metrics.Get("calls").String())
+	}
+}
@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerErrorTranslator translates errors.Error and response.ResponseData returned by handler
+// into grpc status, the business error code is carried in status details, see GetErrorCode.
+// The message is translated to the language of accept-language metadata.
+func UnaryServerErrorTranslator() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToLocalizedStatus(ctx, err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+func StreamServerErrorTranslator() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		if err := handler(srv, stream); err != nil {
+			return ToLocalizedStatus(stream.Context(), err).Err()
+		}
+
+		return nil
+	}
+}
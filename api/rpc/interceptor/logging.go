@@ -0,0 +1,93 @@
+package interceptor
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/utils/log"
+)
+
+// UnaryServerLogger logs every call with request id, method, status code and latency.
+func UnaryServerLogger(logger *logrus.Entry) grpc.UnaryServerInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logCall(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func StreamServerLogger(logger *logrus.Entry) grpc.StreamServerInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		start := time.Now()
+
+		err := handler(srv, stream)
+
+		logCall(stream.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func defaultLogger(logger *logrus.Entry) *logrus.Entry {
+
+	if logger == nil {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return logger
+}
+
+func logCall(ctx context.Context, logger *logrus.Entry, method string, start time.Time, err error) {
+
+	code := status.Code(err)
+
+	entry := log.WithRequestId(ctx, logger).WithFields(logrus.Fields{
+		"method":  method,
+		"code":    code.String(),
+		"latency": time.Since(start),
+	})
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry = entry.WithField("peer", p.Addr.String())
+	}
+
+	if err != nil {
+		if errorCode, ok := GetErrorCode(status.Convert(err)); ok {
+			entry = entry.WithField("errorCode", errorCode)
+		}
+		entry = entry.WithError(err)
+	}
+
+	entry.Log(getLevel(code))
+}
+
+// getLevel logs server side failures as error, client side failures as warning
+func getLevel(code codes.Code) logrus.Level {
+
+	switch code {
+	case codes.OK:
+		return logrus.InfoLevel
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		return logrus.ErrorLevel
+	}
+
+	return logrus.WarnLevel
+}
@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/rpc/utils/log"
+)
+
+// UnaryServerRecovery recovers the panic of handler, and returns codes.Internal to client.
+func UnaryServerRecovery(logger *logrus.Entry) grpc.UnaryServerInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoverPanic(ctx, logger, info.FullMethod, recovered)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerRecovery(logger *logrus.Entry) grpc.StreamServerInterceptor {
+
+	logger = defaultLogger(logger)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoverPanic(stream.Context(), logger, info.FullMethod, recovered)
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func recoverPanic(ctx context.Context, logger *logrus.Entry, method string, recovered interface{}) error {
+
+	log.WithRequestId(ctx, logger).
+		WithField("panic", recovered).
+		WithField("method", method).
+		WithField("stack", string(debug.Stack())).
+		Error("panic recovered")
+
+	return ToLocalizedStatus(ctx, errors.ServerInternalError).Err()
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+// UnaryClientRequestId sends the request id of context to server by metadata.
+// The deadline of context is sent by grpc itself.
+func UnaryClientRequestId() grpc.UnaryClientInterceptor {
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		return invoker(outgoingRequestIdContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientRequestId() grpc.StreamClientInterceptor {
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		return streamer(outgoingRequestIdContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoingRequestIdContext(ctx context.Context) context.Context {
+
+	requestId := requestid.GetRequestIdFromRPCContext(ctx)
+	if requestId == "" {
+		return ctx
+	}
+
+	return requestid.NewRPCContext(ctx, requestId)
+}
+
+// UnaryServerRequestId extracts request id from metadata, generates one if missing,
+// the id can be got by requestid.GetRequestIdFromRPCContext and is echoed in response header.
+func UnaryServerRequestId() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		return handler(incomingRequestIdContext(ctx), req)
+	}
+}
+
+func StreamServerRequestId() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		return handler(srv, &contextServerStream{
+			ServerStream: stream,
+			ctx:          incomingRequestIdContext(stream.Context()),
+		})
+	}
+}
+
+func incomingRequestIdContext(ctx context.Context) context.Context {
+
+	requestId := requestid.GetRequestIdFromRPCContext(ctx)
+	if requestId == "" {
+		requestId = requestid.GenerateRequestId()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, requestId))
+
+	return context.WithValue(ctx, requestid.Key, requestId)
+}
+
+// contextServerStream replaces the context of server stream
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextServerStream) Context() context.Context {
+
+	return stream.ctx
+}
@@ -0,0 +1,80 @@
+package interceptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
+)
+
+func TestUnaryServerRequestId(t *testing.T) {
+
+	tests := []struct {
+		input context.Context
+		want  string
+	}{
+		{
+			input: metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "abc")),
+			want:  "abc",
+		},
+		{
+			input: context.Background(),
+			want:  "",
+		},
+	}
+
+	for _, test := range tests {
+
+		var actual string
+		_, err := UnaryServerRequestId()(test.input, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			actual = requestid.GetRequestIdFromRPCContext(ctx)
+			return nil, nil
+		})
+
+		assert.Nil(t, err)
+		if test.want == "" {
+			assert.Len(t, actual, 24)
+			continue
+		}
+		assert.Equal(t, test.want, actual)
+	}
+}
+
+func TestUnaryClientRequestId(t *testing.T) {
+
+	tests := []struct {
+		input context.Context
+		want  []string
+	}{
+		{
+			input: context.WithValue(context.Background(), requestid.Key, "abc"),
+			want:  []string{"abc"},
+		},
+		{
+			input: metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "def")),
+			want:  []string{"def"},
+		},
+		{
+			input: context.Background(),
+			want:  nil,
+		},
+	}
+
+	for _, test := range tests {
+
+		var actual []string
+		err := UnaryClientRequestId()(test.input, "/test", nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				actual = md.Get(requestid.MetadataKey)
+				return nil
+			})
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.want, actual)
+	}
+}
@@ -0,0 +1,235 @@
+package interceptor
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/i18n"
+)
+
+// DetailErrorCodeKey is the field name of business error code in status details
+const DetailErrorCodeKey = "errorCode"
+
+// ToStatus translates error to grpc status, the business error code is carried in status details.
+func ToStatus(err error) *status.Status {
+
+	if err == nil {
+		return nil
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	switch e := err.(type) {
+	case *response.ResponseData:
+		return newStatus(HTTPStatusToCode(getHTTPStatus(e.Status, e.Code)), e.Code, e.Message, nil)
+	case response.ResponseData:
+		return newStatus(HTTPStatusToCode(getHTTPStatus(e.Status, e.Code)), e.Code, e.Message, nil)
+	case validation.Error, validation.Errors:
+		data := response.ResponseData{
+			Status: http.StatusBadRequest,
+			Code:   errors.CodeRequestParamError,
+		}.WithError(err)
+		return newStatus(codes.InvalidArgument, data.Code, data.Message, err)
+	}
+
+	// the error may wrap an errors.Error, the message of cause is not sent to client
+	if e, ok := errors.FromError(err); ok {
+		code := e.GetGRPCCode()
+		if code == codes.Unknown {
+			code = HTTPStatusToCode(e.GetHTTPStatus())
+		}
+		return newStatus(code, e.Code(), e.Message(), nil)
+	}
+
+	switch err {
+	case context.Canceled:
+		return status.New(codes.Canceled, err.Error())
+	case context.DeadlineExceeded:
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+
+	return status.New(codes.Unknown, err.Error())
+}
+
+// ToLocalizedStatus translates error to grpc status with message in the language of accept-language metadata.
+func ToLocalizedStatus(ctx context.Context, err error) *status.Status {
+
+	lang := i18n.GetLanguageFromRPCContext(ctx)
+
+	switch e := err.(type) {
+	case *response.ResponseData:
+		err = e.WithMessage(i18n.TranslateCode(lang, e.Code, e.Message))
+	case response.ResponseData:
+		err = e.WithMessage(i18n.TranslateCode(lang, e.Code, e.Message))
+	default:
+		err = i18n.LocalizeError(lang, err)
+	}
+
+	return ToStatus(err)
+}
+
+// GetErrorCode returns the business error code carried by status details.
+func GetErrorCode(st *status.Status) (code int, ok bool) {
+
+	if st == nil {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+
+		detailStruct, isStruct := detail.(*structpb.Struct)
+		if !isStruct {
+			continue
+		}
+
+		value, exist := detailStruct.GetFields()[DetailErrorCodeKey]
+		if !exist {
+			continue
+		}
+
+		return int(value.GetNumberValue()), true
+	}
+
+	return 0, false
+}
+
+// getHTTPStatus infers http status from error code if not set
+func getHTTPStatus(httpStatus int, errorCode int) int {
+
+	if httpStatus == 0 || httpStatus == http.StatusOK {
+		return errorCode / 1000 // 业务错误码为 HTTP 状态码 * 1000 + 序号
+	}
+
+	return httpStatus
+}
+
+func newStatus(code codes.Code, errorCode int, message string, validationError error) *status.Status {
+
+	st := status.New(code, message)
+
+	details := []proto.Message{
+		&structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				DetailErrorCodeKey: {Kind: &structpb.Value_NumberValue{NumberValue: float64(errorCode)}},
+			},
+		},
+	}
+
+	if badRequest := toBadRequest(validationError); badRequest != nil {
+		details = append(details, badRequest)
+	}
+
+	for _, detail := range details {
+
+		withDetails, err := st.WithDetails(detail)
+		if err != nil {
+			continue
+		}
+		st = withDetails
+	}
+
+	return st
+}
+
+func toBadRequest(err error) *errdetails.BadRequest {
+
+	validationErrors, ok := err.(validation.Errors)
+	if !ok || len(validationErrors) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for field, fieldError := range validationErrors {
+		if fieldError == nil {
+			continue
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fieldError.Error(),
+		})
+	}
+
+	return badRequest
+}
+
+// HTTPStatusToCode maps http status to grpc code, see https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+func HTTPStatusToCode(httpStatus int) codes.Code {
+
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case httpStatus >= 400 && httpStatus < 500:
+		return codes.InvalidArgument
+	case httpStatus >= 500 && httpStatus < 600:
+		return codes.Internal
+	}
+
+	return codes.Unknown
+}
+
+// CodeToHTTPStatus maps grpc code to http status
+func CodeToHTTPStatus(code codes.Code) int {
+
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
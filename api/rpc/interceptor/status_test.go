@@ -0,0 +1,140 @@
+package interceptor
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+)
+
+func TestToStatus(t *testing.T) {
+
+	tests := []struct {
+		input         error
+		wantCode      codes.Code
+		wantMessage   string
+		wantErrorCode int
+		wantHasCode   bool
+	}{
+		{
+			input:         errors.RequestTokenExpired,
+			wantCode:      codes.Unauthenticated,
+			wantMessage:   "request 'token' is expired",
+			wantErrorCode: errors.CodeRequestTokenExpired,
+			wantHasCode:   true,
+		},
+		{
+			input:         &errors.ServerInternalError,
+			wantCode:      codes.Internal,
+			wantMessage:   "Internal Server Error",
+			wantErrorCode: errors.CodeServerInternalError,
+			wantHasCode:   true,
+		},
+		{
+			input:         fmt.Errorf("query user: %w", errors.RequestPathError.Wrap(fmt.Errorf("sql: no rows"))),
+			wantCode:      codes.NotFound,
+			wantMessage:   "request path error",
+			wantErrorCode: errors.CodeRequestPathError,
+			wantHasCode:   true,
+		},
+		{
+			input:         errors.Error{ErrorCode: 409001, ErrorMessage: "conflict"},
+			wantCode:      codes.AlreadyExists,
+			wantMessage:   "conflict",
+			wantErrorCode: 409001,
+			wantHasCode:   true,
+		},
+		{
+			input:         errors.ServerInternalError.WithMessage("服务内部错误"),
+			wantCode:      codes.Internal,
+			wantMessage:   "服务内部错误",
+			wantErrorCode: errors.CodeServerInternalError,
+			wantHasCode:   true,
+		},
+		{
+			input:         &response.ResponseData{Status: http.StatusForbidden, Code: 403001, Message: "forbidden"},
+			wantCode:      codes.PermissionDenied,
+			wantMessage:   "forbidden",
+			wantErrorCode: 403001,
+			wantHasCode:   true,
+		},
+		{
+			input:         response.ResponseData{Code: 404001, Message: "not found"},
+			wantCode:      codes.NotFound,
+			wantMessage:   "not found",
+			wantErrorCode: 404001,
+			wantHasCode:   true,
+		},
+		{
+			input:       status.Error(codes.AlreadyExists, "exists"),
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "exists",
+		},
+		{
+			input:       context.DeadlineExceeded,
+			wantCode:    codes.DeadlineExceeded,
+			wantMessage: context.DeadlineExceeded.Error(),
+		},
+		{
+			input:       fmt.Errorf("unknown"),
+			wantCode:    codes.Unknown,
+			wantMessage: "unknown",
+		},
+	}
+
+	for _, test := range tests {
+
+		st := ToStatus(test.input)
+		assert.Equal(t, test.wantCode, st.Code(), test.input)
+		assert.Equal(t, test.wantMessage, st.Message(), test.input)
+
+		errorCode, ok := GetErrorCode(st)
+		assert.Equal(t, test.wantHasCode, ok, test.input)
+		assert.Equal(t, test.wantErrorCode, errorCode, test.input)
+	}
+
+	assert.Nil(t, ToStatus(nil))
+}
+
+func TestToStatus_ValidationErrors(t *testing.T) {
+
+	st := ToStatus(validation.Errors{"name": validation.ErrRequired})
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	errorCode, ok := GetErrorCode(st)
+	assert.True(t, ok)
+	assert.Equal(t, errors.CodeRequestParamError, errorCode)
+
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if value, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = value
+		}
+	}
+
+	if assert.NotNil(t, badRequest) && assert.Len(t, badRequest.FieldViolations, 1) {
+		assert.Equal(t, "name", badRequest.FieldViolations[0].Field)
+	}
+}
+
+func TestCodeToHTTPStatus(t *testing.T) {
+
+	for _, httpStatus := range []int{
+		http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+		http.StatusNotFound, http.StatusConflict, http.StatusTooManyRequests, http.StatusServiceUnavailable,
+	} {
+		assert.Equal(t, httpStatus, CodeToHTTPStatus(HTTPStatusToCode(httpStatus)))
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, CodeToHTTPStatus(codes.Internal))
+	assert.Equal(t, codes.Internal, HTTPStatusToCode(http.StatusBadGateway))
+}
@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestUnaryServerTimeout(t *testing.T) {
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	tests := []struct {
+		ctx     context.Context
+		timeout time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+		wantSet bool
+	}{
+		{
+			ctx:     context.Background(),
+			timeout: time.Second,
+			wantMax: time.Second,
+			wantSet: true,
+		},
+		{
+			ctx:     parent,
+			timeout: time.Second,
+			wantMin: time.Minute,
+			wantMax: time.Hour,
+			wantSet: true,
+		},
+		{
+			ctx:     context.Background(),
+			timeout: 0,
+			wantSet: false,
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := UnaryServerTimeout(test.timeout)(test.ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, test.wantSet, ok)
+			if ok {
+				remain := time.Until(deadline)
+				assert.True(t, remain >= test.wantMin && remain <= test.wantMax, remain)
+			}
+			return nil, nil
+		})
+
+		assert.Nil(t, err)
+	}
+}
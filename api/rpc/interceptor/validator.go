@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/validator"
+)
+
+// UnaryServerValidator validates the request message which implements validator.Validator before handler,
+// the validation error is returned as codes.InvalidArgument.
+func UnaryServerValidator() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		if err := validate(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerValidator validates every message received from client stream.
+func StreamServerValidator() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		return handler(srv, &validateServerStream{ServerStream: stream})
+	}
+}
+
+func validate(ctx context.Context, req interface{}) error {
+
+	v, ok := req.(validator.Validator)
+	if !ok {
+		return nil
+	}
+
+	if err := v.Validate(); err != nil {
+		return ToLocalizedStatus(ctx, err).Err()
+	}
+
+	return nil
+}
+
+type validateServerStream struct {
+	grpc.ServerStream
+}
+
+func (stream *validateServerStream) RecvMsg(m interface{}) error {
+
+	if err := stream.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validate(stream.Context(), m)
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// UserClaims is the claims of user token, services define their claims by embedding Claims, e.g.
+//
+//	type ShopClaims struct {
+//		auth.Claims
+//		ShopId uint64 `json:"shopId"`
+//	}
+//
+//	auth.RegisterClaims(func() auth.UserClaims { return &ShopClaims{} })
+//
+// then the middlewares decode tokens into *ShopClaims.
+type UserClaims interface {
+	GetUserId() uint64
+	SetUserId(userId uint64)
+	GetTenant() string
+	GetRoles() []string
+	HasRole(role string) bool
+}
+
+// Claims is the standard user claims, the user id is the sub claim, and the custom fields are the claims of the same json name.
+type Claims struct {
+	UserId uint64   `json:"-"`
+	Tenant string   `json:"tenant,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+func (claims *Claims) GetUserId() uint64 {
+	return claims.UserId
+}
+
+func (claims *Claims) SetUserId(userId uint64) {
+	claims.UserId = userId
+}
+
+func (claims *Claims) GetTenant() string {
+	return claims.Tenant
+}
+
+func (claims *Claims) GetRoles() []string {
+	return claims.Roles
+}
+
+func (claims *Claims) HasRole(role string) bool {
+
+	for _, item := range claims.Roles {
+		if item == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	claimsLock    sync.RWMutex
+	claimsFactory = func() UserClaims { return &Claims{} }
+)
+
+// RegisterClaims sets the factory of claims struct, it should be called when service initializing.
+func RegisterClaims(factory func() UserClaims) {
+
+	claimsLock.Lock()
+	defer claimsLock.Unlock()
+
+	claimsFactory = factory
+}
+
+// NewUserClaims returns an empty claims of registered struct.
+func NewUserClaims() UserClaims {
+
+	claimsLock.RLock()
+	defer claimsLock.RUnlock()
+
+	return claimsFactory()
+}
+
+// DecodeClaims decodes the token claims into the registered claims struct,
+// the fields in info claim of NewUserJwtToken are decoded too, the claims of the same name take precedence.
+func DecodeClaims(claims jwt.MapClaims) (UserClaims, error) {
+
+	userClaims := NewUserClaims()
+
+	if infoJSON, ok := claims["info"].(string); ok && infoJSON != "" {
+		// the info may have other types of fields, they are ignored as before
+		_ = decodeJSON([]byte(infoJSON), userClaims)
+	}
+
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = decodeJSON(claimsJSON, userClaims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	userId, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return nil, errorTokenInvalid
+	}
+
+	userClaims.SetUserId(userId)
+
+	return userClaims, nil
+}
+
+// EncodeClaims returns the custom claims of IssueClaims, the user id is the sub claim set by JWT.
+func EncodeClaims(userClaims UserClaims) (jwt.MapClaims, error) {
+
+	claimsJSON, err := json.Marshal(userClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	if err = decodeJSON(claimsJSON, &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// decodeJSON decodes the numbers of interface{} values as json.Number instead of float64,
+// so the ids above 2^53 in custom claims are not rounded.
+func decodeJSON(data []byte, v interface{}) error {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+// getInt64Claim returns the numeric claim, it's json.Number if parsed by JWT.Parse, or float64 if decoded by json.Unmarshal.
+func getInt64Claim(claims jwt.MapClaims, name string) int64 {
+
+	switch value := claims[name].(type) {
+	case json.Number:
+		number, _ := value.Int64()
+		return number
+	case float64:
+		return int64(value)
+	case int64:
+		return value
+	}
+
+	return 0
+}
+
+// IssueUserClaims issues token of the claims struct.
+func (j *JWT) IssueUserClaims(userClaims UserClaims) (string, int64, error) {
+
+	claims, err := EncodeClaims(userClaims)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return j.IssueClaims(userClaims.GetUserId(), nil, claims)
+}
+
+type claimsContextKey struct{}
+
+// NewContext returns context carries the claims, it's used by gin and grpc authentication.
+func NewContext(ctx context.Context, userClaims UserClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, userClaims)
+}
+
+// FromContext returns the claims of authenticated user.
+func FromContext(ctx context.Context) (UserClaims, bool) {
+	userClaims, ok := ctx.Value(claimsContextKey{}).(UserClaims)
+	return userClaims, ok
+}
+
+// GetUserIdFromContext returns 0 if the context is not authenticated.
+func GetUserIdFromContext(ctx context.Context) uint64 {
+
+	if userClaims, ok := FromContext(ctx); ok {
+		return userClaims.GetUserId()
+	}
+
+	return 0
+}
+
+type infoContextKey struct{}
+type tokenContextKey struct{}
+
+// NewInfoContext returns context carries the user information of token.
+func NewInfoContext(ctx context.Context, info map[string]interface{}) context.Context {
+	return context.WithValue(ctx, infoContextKey{}, info)
+}
+
+// GetUserInfoFromContext returns nil if the context is not authenticated.
+func GetUserInfoFromContext(ctx context.Context) map[string]interface{} {
+	info, _ := ctx.Value(infoContextKey{}).(map[string]interface{})
+	return info
+}
+
+// NewTokenContext returns context carries the token of caller, it's forwarded to the downstream services.
+func NewTokenContext(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+func GetTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenContextKey{}).(string)
+	return token
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type shopClaims struct {
+	Claims
+	ShopId uint64 `json:"shopId"`
+}
+
+func TestDecodeClaims(t *testing.T) {
+
+	j, err := NewJWT(JWTKeys(NewHMACKey("", []byte("secret"))))
+	assert.NoError(t, err)
+
+	RegisterClaims(func() UserClaims { return &shopClaims{} })
+	defer RegisterClaims(func() UserClaims { return &Claims{} })
+
+	token, _, err := j.IssueUserClaims(&shopClaims{
+		Claims: Claims{UserId: 1, Tenant: "wanxin", Roles: []string{"admin"}},
+		ShopId: 2,
+	})
+	assert.NoError(t, err)
+
+	claims, err := j.Parse(token, testLog)
+	assert.NoError(t, err)
+
+	userClaims, err := DecodeClaims(claims)
+	assert.NoError(t, err)
+
+	shop, ok := userClaims.(*shopClaims)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), shop.GetUserId())
+	assert.Equal(t, "wanxin", shop.GetTenant())
+	assert.True(t, shop.HasRole("admin"))
+	assert.False(t, shop.HasRole("user"))
+	assert.Equal(t, uint64(2), shop.ShopId)
+
+	// the info of NewUserJwtToken is decoded too
+	token, _, err = NewUserJwtToken(3, map[string]interface{}{"tenant": "info", "shopId": 4, "name": "test"}, "secret")
+	assert.NoError(t, err)
+
+	claims, err = ParseJWTToken(token, "secret", testLog)
+	assert.NoError(t, err)
+
+	userClaims, err = DecodeClaims(claims)
+	assert.NoError(t, err)
+	assert.Equal(t, &shopClaims{Claims: Claims{UserId: 3, Tenant: "info"}, ShopId: 4}, userClaims)
+
+	_, err = DecodeClaims(jwt.MapClaims{"sub": "1", "roles": "admin"})
+	assert.Error(t, err)
+
+	_, err = DecodeClaims(jwt.MapClaims{})
+	assert.Equal(t, errorTokenInvalid, err)
+}
+
+func TestClaimsContext(t *testing.T) {
+
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), GetUserIdFromContext(ctx))
+
+	ctx = NewContext(ctx, &Claims{UserId: 1, Roles: []string{"admin"}})
+
+	userClaims, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.True(t, userClaims.HasRole("admin"))
+	assert.Equal(t, uint64(1), GetUserIdFromContext(ctx))
+}
+
+func TestTokenManager_IssueUserClaims(t *testing.T) {
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	pair, err := manager.IssueUserClaims(ctx, &Claims{UserId: 1, Tenant: "wanxin"})
+	assert.NoError(t, err)
+
+	pair, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.NoError(t, err)
+
+	claims, err := manager.GetJWT().Parse(pair.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	userClaims, err := DecodeClaims(claims)
+	assert.NoError(t, err)
+	assert.Equal(t, &Claims{UserId: 1, Tenant: "wanxin"}, userClaims)
+}
+
+func TestClaims_LargeNumber(t *testing.T) {
+
+	const shopId = 432101234567890123 // above 2^53, float64 rounds it
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	RegisterClaims(func() UserClaims { return &shopClaims{} })
+	defer RegisterClaims(func() UserClaims { return &Claims{} })
+
+	claims, err := EncodeClaims(&shopClaims{ShopId: shopId})
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("432101234567890123"), claims["shopId"])
+
+	pair, err := manager.IssueUserClaims(ctx, &shopClaims{Claims: Claims{UserId: 1}, ShopId: shopId})
+	assert.NoError(t, err)
+
+	pair, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.NoError(t, err)
+
+	claims, err = manager.GetJWT().Parse(pair.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	userClaims, err := DecodeClaims(claims)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(shopId), userClaims.(*shopClaims).ShopId)
+
+	// the refresh token record saved as json by RedisTokenStore
+	value, err := json.Marshal(&RefreshTokenRecord{Claims: map[string]interface{}{"shopId": uint64(shopId)}})
+	assert.NoError(t, err)
+
+	record := &RefreshTokenRecord{}
+	assert.NoError(t, decodeJSON(value, record))
+	assert.Equal(t, json.Number("432101234567890123"), record.Claims["shopId"])
+}
+
+func TestGetInt64Claim(t *testing.T) {
+
+	claims := jwt.MapClaims{
+		"number": json.Number("1600000000"),
+		"float":  float64(1600000000),
+		"int":    int64(1600000000),
+		"string": "1600000000",
+	}
+
+	assert.Equal(t, int64(1600000000), getInt64Claim(claims, "number"))
+	assert.Equal(t, int64(1600000000), getInt64Claim(claims, "float"))
+	assert.Equal(t, int64(1600000000), getInt64Claim(claims, "int"))
+	assert.Equal(t, int64(0), getInt64Claim(claims, "string"))
+	assert.Equal(t, int64(0), getInt64Claim(claims, "missing"))
+}
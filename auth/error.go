@@ -7,6 +7,9 @@ import (
 var errorEmptyString = errors.New("token string empty")
 var errorTokenExpired = errors.New("token was expired")
 var errorTokenInvalid = errors.New("token is invalid")
+var errorTokenRevoked = errors.New("token was revoked")
+var errorRefreshTokenInvalid = errors.New("refresh token is invalid")
+var errorRefreshTokenReused = errors.New("refresh token was reused")
 
 func IsTokenExpired(err error) bool {
 	return err == errorTokenExpired
@@ -15,3 +18,45 @@ func IsTokenExpired(err error) bool {
 func IsTokenInvalid(err error) bool {
 	return err == errorTokenInvalid
 }
+
+func IsTokenRevoked(err error) bool {
+	return err == errorTokenRevoked
+}
+
+func IsRefreshTokenInvalid(err error) bool {
+	return err == errorRefreshTokenInvalid
+}
+
+// IsRefreshTokenReused means the refresh token was stolen probably, the token family has been revoked.
+func IsRefreshTokenReused(err error) bool {
+	return err == errorRefreshTokenReused
+}
+
+var errorSignatureMissing = errors.New("signature headers are missing")
+var errorSignatureInvalid = errors.New("signature is invalid")
+var errorSignatureExpired = errors.New("signature timestamp is out of allowed skew")
+var errorNonceReused = errors.New("signature nonce was used")
+
+func IsSignatureMissing(err error) bool {
+	return err == errorSignatureMissing
+}
+
+// IsSignatureInvalid means the access key is unknown or the signature is mismatched.
+func IsSignatureInvalid(err error) bool {
+	return err == errorSignatureInvalid
+}
+
+func IsSignatureExpired(err error) bool {
+	return err == errorSignatureExpired
+}
+
+func IsNonceReused(err error) bool {
+	return err == errorNonceReused
+}
+
+var errorSessionSuperseded = errors.New("session was superseded by another login")
+
+// IsSessionSuperseded means the user logged in on another device of the same device class.
+func IsSessionSuperseded(err error) bool {
+	return err == errorSessionSuperseded
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var errorKeyCannotSign = errors.New("key has no private key to sign")
+
+// Key is a signing key identified by kid, the verify only key is loaded from a public key,
+// e.g. the old keys in rotation or the keys of other services.
+type Key struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func (key *Key) GetId() string {
+	return key.id
+}
+
+func (key *Key) GetAlgorithm() string {
+	return key.method.Alg()
+}
+
+func (key *Key) CanSign() bool {
+	return key.signKey != nil
+}
+
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{id: id, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+func NewRSAKey(id string, privateKey *rsa.PrivateKey) *Key {
+	return &Key{id: id, method: jwt.SigningMethodRS256, signKey: privateKey, verifyKey: &privateKey.PublicKey}
+}
+
+func NewRSAPublicKey(id string, publicKey *rsa.PublicKey) *Key {
+	return &Key{id: id, method: jwt.SigningMethodRS256, verifyKey: publicKey}
+}
+
+func NewECDSAKey(id string, privateKey *ecdsa.PrivateKey) *Key {
+	return &Key{id: id, method: jwt.SigningMethodES256, signKey: privateKey, verifyKey: &privateKey.PublicKey}
+}
+
+func NewECDSAPublicKey(id string, publicKey *ecdsa.PublicKey) *Key {
+	return &Key{id: id, method: jwt.SigningMethodES256, verifyKey: publicKey}
+}
+
+func NewEd25519Key(id string, privateKey ed25519.PrivateKey) *Key {
+	return &Key{id: id, method: SigningMethodEdDSA, signKey: privateKey, verifyKey: privateKey.Public()}
+}
+
+func NewEd25519PublicKey(id string, publicKey ed25519.PublicKey) *Key {
+	return &Key{id: id, method: SigningMethodEdDSA, verifyKey: publicKey}
+}
+
+// LoadKeyFile loads key of algorithm from file, the file of HS256 is the secret,
+// and the file of other algorithms is PEM encoded private key or public key.
+func LoadKeyFile(id, algorithm, file string) (*Key, error) {
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseKey(id, algorithm, content)
+	if err != nil {
+		return nil, fmt.Errorf("parse key file %s error: %w", file, err)
+	}
+
+	return key, nil
+}
+
+// ParseKey parses the secret of HS256, or PEM encoded private key or public key of other algorithms.
+func ParseKey(id, algorithm string, content []byte) (*Key, error) {
+
+	switch algorithm {
+	case AlgorithmHS256:
+		secret := []byte(strings.TrimSpace(string(content)))
+		if len(secret) == 0 {
+			return nil, errors.New("empty secret")
+		}
+		return NewHMACKey(id, secret), nil
+
+	case AlgorithmRS256:
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(content); err == nil {
+			return NewRSAKey(id, privateKey), nil
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSAPublicKey(id, publicKey), nil
+
+	case AlgorithmES256:
+		if privateKey, err := jwt.ParseECPrivateKeyFromPEM(content); err == nil {
+			return NewECDSAKey(id, privateKey), nil
+		}
+		publicKey, err := jwt.ParseECPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, err
+		}
+		return NewECDSAPublicKey(id, publicKey), nil
+
+	case AlgorithmEdDSA:
+		return parseEd25519Key(id, content)
+	}
+
+	return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+}
+
+func parseEd25519Key(id string, content []byte) (*Key, error) {
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		privateKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return NewEd25519Key(id, privateKey), nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	return NewEd25519PublicKey(id, publicKey), nil
+}
+
+// JWK is the json web key of public key, RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key, HMAC key is not published.
+func (key *Key) JWK() (JWK, bool) {
+
+	jwk := JWK{
+		KeyId:     key.id,
+		Use:       "sig",
+		Algorithm: key.method.Alg(),
+	}
+
+	switch publicKey := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64URL(publicKey.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = encodeBase64URL(padBytes(publicKey.X.Bytes(), size))
+		jwk.Y = encodeBase64URL(padBytes(publicKey.Y.Bytes(), size))
+
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeBase64URL(publicKey)
+
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// padBytes pads the coordinates of elliptic curve to the size of curve, RFC 7518 6.2.1.2
+func padBytes(data []byte, size int) []byte {
+
+	if len(data) >= size {
+		return data
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(data):], data)
+
+	return padded
+}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/database"
+)
+
+// AnyPermission is granted all permissions, e.g. admin: ["*"], and "order:*" is granted the permissions start with "order:".
+const AnyPermission = "*"
+
+// Requirement is required by routes or rpc methods, the user must have any of roles and all of permissions.
+type Requirement struct {
+	Roles       []string
+	Permissions []string
+}
+
+// PolicyLoader loads the permissions of roles.
+type PolicyLoader interface {
+	Load() (map[string][]string, error)
+}
+
+// Policy maps roles to permissions, and authorizes user claims by requirements.
+// The mappings are reloaded from loader periodically if reload interval is set.
+type Policy struct {
+	loader         PolicyLoader
+	reloadInterval time.Duration
+	logger         *logrus.Entry
+
+	lock  sync.RWMutex
+	roles map[string][]string
+	stop  chan struct{}
+}
+
+type PolicyOption func(policy *Policy)
+
+func PolicySource(loader PolicyLoader) PolicyOption {
+	return func(policy *Policy) {
+		policy.loader = loader
+	}
+}
+
+// PolicyReloadInterval reloads the policy by loader, so the changes of file or database take effect without restart.
+func PolicyReloadInterval(interval time.Duration) PolicyOption {
+	return func(policy *Policy) {
+		policy.reloadInterval = interval
+	}
+}
+
+func PolicyRoles(roles map[string][]string) PolicyOption {
+	return func(policy *Policy) {
+		policy.roles = roles
+	}
+}
+
+func PolicyLogger(logger *logrus.Entry) PolicyOption {
+	return func(policy *Policy) {
+		policy.logger = logger
+	}
+}
+
+// NewPolicy returns the policy of roles option, call Start to load it by loader.
+func NewPolicy(options ...PolicyOption) *Policy {
+
+	policy := &Policy{
+		logger: logrus.NewEntry(logrus.StandardLogger()),
+		roles:  make(map[string][]string),
+	}
+
+	for _, option := range options {
+		option(policy)
+	}
+
+	return policy
+}
+
+// Start loads the policy by loader, and starts reloading if interval is set, call Close to stop it.
+func (policy *Policy) Start() error {
+
+	if err := policy.Reload(); err != nil {
+		return err
+	}
+
+	if policy.reloadInterval > 0 && policy.loader != nil && policy.stop == nil {
+		policy.stop = make(chan struct{})
+		go policy.watch(policy.stop)
+	}
+
+	return nil
+}
+
+// Reload loads the policy by loader, the current policy is kept if loading failed.
+func (policy *Policy) Reload() error {
+
+	if policy.loader == nil {
+		return nil
+	}
+
+	roles, err := policy.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	policy.SetRoles(roles)
+	return nil
+}
+
+func (policy *Policy) SetRoles(roles map[string][]string) {
+
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	policy.roles = roles
+}
+
+func (policy *Policy) GetPermissions(role string) []string {
+
+	policy.lock.RLock()
+	defer policy.lock.RUnlock()
+
+	return policy.roles[role]
+}
+
+// HasPermission checks any of roles is granted the permission.
+func (policy *Policy) HasPermission(roles []string, permission string) bool {
+
+	policy.lock.RLock()
+	defer policy.lock.RUnlock()
+
+	for _, role := range roles {
+		for _, granted := range policy.roles[role] {
+			if matchPermission(granted, permission) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Authorize checks the user has any of required roles and all of required permissions,
+// the nil policy authorizes roles only.
+func (policy *Policy) Authorize(claims UserClaims, requirement Requirement) bool {
+
+	if claims == nil {
+		return false
+	}
+
+	if policy == nil && len(requirement.Permissions) > 0 {
+		return false
+	}
+
+	if len(requirement.Roles) > 0 {
+
+		matched := false
+		for _, role := range requirement.Roles {
+			if claims.HasRole(role) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for _, permission := range requirement.Permissions {
+		if !policy.HasPermission(claims.GetRoles(), permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (policy *Policy) Close() {
+
+	if policy.stop != nil {
+		close(policy.stop)
+		policy.stop = nil
+	}
+}
+
+func (policy *Policy) watch(stop chan struct{}) {
+
+	ticker := time.NewTicker(policy.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := policy.Reload(); err != nil {
+				policy.logger.WithError(err).Warn("reload authorization policy failed")
+			}
+		}
+	}
+}
+
+func matchPermission(granted, permission string) bool {
+
+	if granted == AnyPermission || granted == permission {
+		return true
+	}
+
+	return strings.HasSuffix(granted, AnyPermission) && strings.HasPrefix(permission, strings.TrimSuffix(granted, AnyPermission))
+}
+
+// FilePolicyLoader loads yaml or json file of role to permissions, e.g.
+//
+//	admin: ["*"]
+//	editor: ["article:read", "article:write"]
+//
+// the file is parsed only if it's modified after last loading.
+type FilePolicyLoader struct {
+	path    string
+	lock    sync.Mutex
+	modTime time.Time
+	roles   map[string][]string
+}
+
+func NewFilePolicyLoader(path string) *FilePolicyLoader {
+	return &FilePolicyLoader{path: path}
+}
+
+func (loader *FilePolicyLoader) Load() (map[string][]string, error) {
+
+	loader.lock.Lock()
+	defer loader.lock.Unlock()
+
+	info, err := os.Stat(loader.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if loader.roles != nil && info.ModTime().Equal(loader.modTime) {
+		return loader.roles, nil
+	}
+
+	content, err := ioutil.ReadFile(loader.path)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string][]string)
+
+	switch strings.ToLower(filepath.Ext(loader.path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &roles)
+	case ".json":
+		err = json.Unmarshal(content, &roles)
+	default:
+		return nil, fmt.Errorf("unsupported policy file %s", loader.path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse policy file %s error: %w", loader.path, err)
+	}
+
+	loader.roles = roles
+	loader.modTime = info.ModTime()
+
+	return roles, nil
+}
+
+// DatabasePolicyLoader loads the rows of role and permission columns from table of data/database connection.
+type DatabasePolicyLoader struct {
+	connectionKey    string
+	table            string
+	roleColumn       string
+	permissionColumn string
+}
+
+func NewDatabasePolicyLoader(connectionKey, table, roleColumn, permissionColumn string) *DatabasePolicyLoader {
+	return &DatabasePolicyLoader{
+		connectionKey:    connectionKey,
+		table:            table,
+		roleColumn:       roleColumn,
+		permissionColumn: permissionColumn,
+	}
+}
+
+func (loader *DatabasePolicyLoader) Load() (map[string][]string, error) {
+
+	db := database.GetDB(loader.connectionKey)
+	if db == nil {
+		return nil, errors.New(loader.connectionKey + " database connection not exist")
+	}
+
+	rows, err := db.Table(loader.table).Select([]string{loader.roleColumn, loader.permissionColumn}).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make(map[string][]string)
+	for rows.Next() {
+
+		var role, permission string
+		if err = rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+
+		roles[role] = append(roles[role], permission)
+	}
+
+	return roles, rows.Err()
+}
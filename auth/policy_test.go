@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_HasPermission(t *testing.T) {
+
+	policy := NewPolicy(PolicyRoles(map[string][]string{
+		"admin":    {AnyPermission},
+		"operator": {"order:*", "product:read"},
+	}))
+
+	tests := []struct {
+		roles      []string
+		permission string
+		want       bool
+	}{
+		{roles: []string{"admin"}, permission: "user:delete", want: true},
+		{roles: []string{"operator"}, permission: "order:refund", want: true},
+		{roles: []string{"operator"}, permission: "product:read", want: true},
+		{roles: []string{"operator"}, permission: "product:write", want: false},
+		{roles: []string{"guest", "operator"}, permission: "order:read", want: true},
+		{roles: []string{"guest"}, permission: "order:read", want: false},
+		{roles: nil, permission: "order:read", want: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, policy.HasPermission(test.roles, test.permission), test)
+	}
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+
+	policy := NewPolicy(PolicyRoles(map[string][]string{
+		"operator": {"order:*"},
+	}))
+
+	tests := []struct {
+		policy      *Policy
+		claims      UserClaims
+		requirement Requirement
+		want        bool
+	}{
+		{policy: policy, claims: nil, requirement: Requirement{}, want: false},
+		{policy: policy, claims: &Claims{}, requirement: Requirement{}, want: true},
+		{policy: policy, claims: &Claims{Roles: []string{"operator"}}, requirement: Requirement{Roles: []string{"admin", "operator"}}, want: true},
+		{policy: policy, claims: &Claims{Roles: []string{"guest"}}, requirement: Requirement{Roles: []string{"admin", "operator"}}, want: false},
+		{policy: policy, claims: &Claims{Roles: []string{"operator"}}, requirement: Requirement{Permissions: []string{"order:read", "order:write"}}, want: true},
+		{policy: policy, claims: &Claims{Roles: []string{"operator"}}, requirement: Requirement{Permissions: []string{"order:read", "user:read"}}, want: false},
+		{policy: nil, claims: &Claims{Roles: []string{"admin"}}, requirement: Requirement{Roles: []string{"admin"}}, want: true},
+		{policy: nil, claims: &Claims{Roles: []string{"admin"}}, requirement: Requirement{Permissions: []string{"order:read"}}, want: false},
+	}
+
+	for index, test := range tests {
+		assert.Equal(t, test.want, test.policy.Authorize(test.claims, test.requirement), index)
+	}
+}
+
+func TestFilePolicyLoader(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "policy")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policy.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("operator:\n  - order:read\n"), 0644))
+
+	policy := NewPolicy(PolicySource(NewFilePolicyLoader(path)), PolicyReloadInterval(10*time.Millisecond))
+	assert.NoError(t, policy.Start())
+	defer policy.Close()
+
+	assert.True(t, policy.HasPermission([]string{"operator"}, "order:read"))
+	assert.False(t, policy.HasPermission([]string{"operator"}, "order:write"))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("operator:\n  - order:*\n"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	assert.Eventually(t, func() bool {
+		return policy.HasPermission([]string{"operator"}, "order:write")
+	}, time.Second, 10*time.Millisecond)
+
+	jsonPath := filepath.Join(dir, "policy.json")
+	assert.NoError(t, ioutil.WriteFile(jsonPath, []byte(`{"admin":["*"]}`), 0644))
+
+	roles, err := NewFilePolicyLoader(jsonPath).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*"}, roles["admin"])
+
+	_, err = NewFilePolicyLoader(filepath.Join(dir, "missing.yaml")).Load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/shomali11/util/xstrings"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/idcreator/snowflake"
+)
+
+const (
+	DefaultRefreshTokenLifetime = 30 * 24 * time.Hour
+
+	// FamilyClaim is the claim of access token, it's the family id of refresh tokens issued with it
+	FamilyClaim = "fid"
+)
+
+// TokenPair is responded to client after login or refresh.
+type TokenPair struct {
+	AccessToken          string `json:"accessToken"`
+	AccessTokenExpireAt  int64  `json:"accessTokenExpireAt"`
+	RefreshToken         string `json:"refreshToken"`
+	RefreshTokenExpireAt int64  `json:"refreshTokenExpireAt"`
+}
+
+// RevocationChecker is used by authentication middlewares to reject revoked access tokens.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error)
+}
+
+// TokenManager issues short-lived access tokens by JWT, and rotates refresh tokens saved in TokenStore.
+// Every refresh token can be used once, the tokens rotated from the same login are a family,
+// a used refresh token is presented again means it's stolen, so the whole family is revoked.
+type TokenManager struct {
+	jwt             *JWT
+	store           TokenStore
+	sessions        *SessionManager
+	refreshLifetime time.Duration
+}
+
+type TokenManagerOption func(manager *TokenManager)
+
+// TokenManagerSessions records the session of device class when the tokens are issued with IssueDevice.
+func TokenManagerSessions(sessions *SessionManager) TokenManagerOption {
+	return func(manager *TokenManager) {
+		manager.sessions = sessions
+	}
+}
+
+func TokenManagerRefreshLifetime(lifetime time.Duration) TokenManagerOption {
+	return func(manager *TokenManager) {
+		manager.refreshLifetime = lifetime
+	}
+}
+
+func NewTokenManager(j *JWT, store TokenStore, options ...TokenManagerOption) *TokenManager {
+
+	manager := &TokenManager{
+		jwt:             j,
+		store:           store,
+		refreshLifetime: DefaultRefreshTokenLifetime,
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+func (manager *TokenManager) GetJWT() *JWT {
+	return manager.jwt
+}
+
+// GetSessions returns nil if TokenManagerSessions is not set.
+func (manager *TokenManager) GetSessions() *SessionManager {
+	return manager.sessions
+}
+
+type issueOptions struct {
+	device   string
+	metadata map[string]string
+}
+
+type IssueOption func(options *issueOptions)
+
+// IssueDevice starts the session of device class, e.g. mobile, web, the previous session of it is superseded,
+// the metadata is shown in the list of sessions. It takes effect only if TokenManagerSessions is set.
+func IssueDevice(device string, metadata map[string]string) IssueOption {
+	return func(options *issueOptions) {
+		options.device = device
+		options.metadata = metadata
+	}
+}
+
+// Issue issues tokens of a new family, it's called after user login.
+func (manager *TokenManager) Issue(ctx context.Context, userId uint64, info map[string]interface{}, options ...IssueOption) (*TokenPair, error) {
+
+	record := &RefreshTokenRecord{
+		UserId:         userId,
+		FamilyId:       strconv.FormatUint(snowflake.NextID(), 10),
+		FamilyIssuedAt: time.Now().Unix(),
+		Info:           info,
+	}
+
+	if err := manager.startSession(ctx, record, options...); err != nil {
+		return nil, err
+	}
+
+	return manager.issue(ctx, record)
+}
+
+// IssueUserClaims issues tokens of the claims struct, the claims are kept when refreshing.
+func (manager *TokenManager) IssueUserClaims(ctx context.Context, userClaims UserClaims, options ...IssueOption) (*TokenPair, error) {
+
+	claims, err := EncodeClaims(userClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &RefreshTokenRecord{
+		UserId:         userClaims.GetUserId(),
+		FamilyId:       strconv.FormatUint(snowflake.NextID(), 10),
+		FamilyIssuedAt: time.Now().Unix(),
+		Claims:         claims,
+	}
+
+	if err = manager.startSession(ctx, record, options...); err != nil {
+		return nil, err
+	}
+
+	return manager.issue(ctx, record)
+}
+
+// Refresh uses the refresh token once, and issues new tokens of the same family.
+// The error is checked by IsRefreshTokenInvalid, IsRefreshTokenReused, IsTokenRevoked and IsSessionSuperseded.
+func (manager *TokenManager) Refresh(ctx context.Context, refreshToken string, log *logrus.Entry) (*TokenPair, error) {
+
+	if xstrings.IsBlank(refreshToken) {
+		return nil, errorRefreshTokenInvalid
+	}
+
+	id := hashRefreshToken(refreshToken)
+	log = log.WithField("refreshTokenId", id)
+
+	record, err := manager.store.GetRefreshToken(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil || record.ExpireAt < time.Now().Unix() {
+		log.Warn("refresh token not exist")
+		return nil, errorRefreshTokenInvalid
+	}
+
+	revoked, err := manager.isFamilyRevoked(ctx, record.UserId, record.FamilyId, record.FamilyIssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		log.WithField("familyId", record.FamilyId).Warn("refresh token was revoked")
+		return nil, errorTokenRevoked
+	}
+
+	if record.SessionId != "" && manager.sessions != nil {
+		if err = manager.sessions.Extend(ctx, record.UserId, record.Device, record.SessionId, manager.refreshLifetime); err != nil {
+			log.WithError(err).WithField("sessionId", record.SessionId).Warn("session is not active")
+			return nil, err
+		}
+	}
+
+	first, err := manager.store.UseRefreshToken(ctx, id, time.Until(time.Unix(record.ExpireAt, 0)))
+	if err != nil {
+		return nil, err
+	}
+
+	if !first {
+		log.WithField("familyId", record.FamilyId).
+			WithField("userId", record.UserId).
+			Warn("refresh token was reused, revoke the token family")
+
+		if err = manager.RevokeFamily(ctx, record.FamilyId); err != nil {
+			return nil, err
+		}
+
+		return nil, errorRefreshTokenReused
+	}
+
+	return manager.issue(ctx, record)
+}
+
+// Resolve verifies the access token and checks revocation.
+func (manager *TokenManager) Resolve(ctx context.Context, accessToken string, log *logrus.Entry) (userId uint64, info map[string]interface{}, err error) {
+
+	claims, err := manager.jwt.Parse(accessToken, log)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	revoked, err := manager.IsRevoked(ctx, claims)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if revoked {
+		return 0, nil, errorTokenRevoked
+	}
+
+	return ResolveClaims(claims, log)
+}
+
+// IsRevoked checks the revocations of token id, token family and user.
+func (manager *TokenManager) IsRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+
+	jti, _ := claims["jti"].(string)
+	familyId, _ := claims[FamilyClaim].(string)
+	subject, _ := claims["sub"].(string)
+
+	revocations, err := manager.store.GetRevocations(ctx, "jti:"+jti, "family:"+familyId, "user:"+subject)
+	if err != nil {
+		return false, err
+	}
+
+	if jti != "" && revocations[0] > 0 || familyId != "" && revocations[1] > 0 {
+		return true, nil
+	}
+
+	issuedAt := getInt64Claim(claims, "iat")
+
+	return revocations[2] > 0 && issuedAt < revocations[2], nil
+}
+
+// Logout revokes the access token and its refresh token family, and ends its session.
+func (manager *TokenManager) Logout(ctx context.Context, claims jwt.MapClaims) error {
+
+	if manager.sessions != nil {
+		if err := manager.sessions.End(ctx, claims); err != nil {
+			return err
+		}
+	}
+
+	if jti, _ := claims["jti"].(string); jti != "" {
+
+		expireAt := getInt64Claim(claims, "exp")
+		ttl := time.Until(time.Unix(expireAt, 0)) + manager.jwt.leeway
+
+		if ttl > 0 {
+			if err := manager.store.SetRevocation(ctx, "jti:"+jti, time.Now().Unix(), ttl); err != nil {
+				return err
+			}
+		}
+	}
+
+	if familyId, _ := claims[FamilyClaim].(string); familyId != "" {
+		return manager.RevokeFamily(ctx, familyId)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes the refresh tokens and the access tokens of a login.
+func (manager *TokenManager) RevokeFamily(ctx context.Context, familyId string) error {
+
+	return manager.store.SetRevocation(ctx, "family:"+familyId, time.Now().Unix(), manager.getRevocationTTL())
+}
+
+// RevokeUser logs out everywhere, the tokens of user issued before now are revoked.
+func (manager *TokenManager) RevokeUser(ctx context.Context, userId uint64) error {
+
+	return manager.store.SetRevocation(ctx, "user:"+strconv.FormatUint(userId, 10), time.Now().Unix(), manager.getRevocationTTL())
+}
+
+func (manager *TokenManager) issue(ctx context.Context, record *RefreshTokenRecord) (*TokenPair, error) {
+
+	claims := make(jwt.MapClaims, len(record.Claims)+3)
+	for name, value := range record.Claims {
+		claims[name] = value
+	}
+	claims[FamilyClaim] = record.FamilyId
+
+	if record.SessionId != "" {
+		claims[SessionClaim] = record.SessionId
+		claims[DeviceClaim] = record.Device
+	}
+
+	accessToken, accessTokenExpireAt, err := manager.jwt.IssueClaims(record.UserId, record.Info, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	next := *record
+	next.ExpireAt = time.Now().Add(manager.refreshLifetime).Unix()
+
+	if err = manager.store.SaveRefreshToken(ctx, hashRefreshToken(refreshToken), &next, manager.refreshLifetime); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:          accessToken,
+		AccessTokenExpireAt:  accessTokenExpireAt,
+		RefreshToken:         refreshToken,
+		RefreshTokenExpireAt: next.ExpireAt,
+	}, nil
+}
+
+func (manager *TokenManager) startSession(ctx context.Context, record *RefreshTokenRecord, options ...IssueOption) error {
+
+	issue := &issueOptions{}
+	for _, option := range options {
+		option(issue)
+	}
+
+	if issue.device == "" || manager.sessions == nil {
+		return nil
+	}
+
+	session, err := manager.sessions.Start(ctx, record.UserId, issue.device, issue.metadata, manager.refreshLifetime)
+	if err != nil {
+		return err
+	}
+
+	record.SessionId = session.Id
+	record.Device = session.Device
+
+	return nil
+}
+
+func (manager *TokenManager) isFamilyRevoked(ctx context.Context, userId uint64, familyId string, familyIssuedAt int64) (bool, error) {
+
+	revocations, err := manager.store.GetRevocations(ctx, "family:"+familyId, "user:"+strconv.FormatUint(userId, 10))
+	if err != nil {
+		return false, err
+	}
+
+	return revocations[0] > 0 || revocations[1] > 0 && familyIssuedAt < revocations[1], nil
+}
+
+// getRevocationTTL keeps the revocation until all tokens issued before expired
+func (manager *TokenManager) getRevocationTTL() time.Duration {
+
+	ttl := manager.refreshLifetime
+	if manager.jwt.lifetime > ttl {
+		ttl = manager.jwt.lifetime
+	}
+
+	return ttl + manager.jwt.leeway
+}
+
+func generateRefreshToken() (string, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken is the id of refresh token saved, so the tokens are not leaked by the store
+func hashRefreshToken(refreshToken string) string {
+
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryTokenStore struct {
+	lock        sync.Mutex
+	records     map[string]*RefreshTokenRecord
+	used        map[string]bool
+	revocations map[string]int64
+}
+
+func newMemoryTokenStore() *memoryTokenStore {
+	return &memoryTokenStore{
+		records:     map[string]*RefreshTokenRecord{},
+		used:        map[string]bool{},
+		revocations: map[string]int64{},
+	}
+}
+
+func (store *memoryTokenStore) SaveRefreshToken(ctx context.Context, id string, record *RefreshTokenRecord, ttl time.Duration) error {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.records[id] = record
+	return nil
+}
+
+func (store *memoryTokenStore) GetRefreshToken(ctx context.Context, id string) (*RefreshTokenRecord, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.records[id], nil
+}
+
+func (store *memoryTokenStore) UseRefreshToken(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if store.used[id] {
+		return false, nil
+	}
+
+	store.used[id] = true
+	return true, nil
+}
+
+func (store *memoryTokenStore) SetRevocation(ctx context.Context, key string, revokedAt int64, ttl time.Duration) error {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.revocations[key] = revokedAt
+	return nil
+}
+
+func (store *memoryTokenStore) GetRevocations(ctx context.Context, keys ...string) ([]int64, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	revocations := make([]int64, len(keys))
+	for index, key := range keys {
+		revocations[index] = store.revocations[key]
+	}
+
+	return revocations, nil
+}
+
+func newTestTokenManager(t *testing.T) *TokenManager {
+
+	j, err := NewJWT(JWTKeys(NewHMACKey("hmac", []byte("secret"))), JWTLifetime(15*time.Minute))
+	assert.NoError(t, err)
+
+	return NewTokenManager(j, newMemoryTokenStore(), TokenManagerRefreshLifetime(time.Hour))
+}
+
+func TestTokenManager_Refresh(t *testing.T) {
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	pair, err := manager.Issue(ctx, 1, map[string]interface{}{"name": "test"})
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(15*time.Minute).Unix(), pair.AccessTokenExpireAt, 2)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), pair.RefreshTokenExpireAt, 2)
+
+	userId, info, err := manager.Resolve(ctx, pair.AccessToken, testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userId)
+	assert.Equal(t, "test", info["name"])
+
+	refreshed, err := manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, refreshed.RefreshToken)
+
+	userId, info, err = manager.Resolve(ctx, refreshed.AccessToken, testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userId)
+	assert.Equal(t, "test", info["name"])
+
+	_, err = manager.Refresh(ctx, "unknown", testLog)
+	assert.True(t, IsRefreshTokenInvalid(err))
+
+	_, err = manager.Refresh(ctx, "", testLog)
+	assert.True(t, IsRefreshTokenInvalid(err))
+}
+
+func TestTokenManager_Reuse(t *testing.T) {
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	pair, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	other, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	refreshed, err := manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.NoError(t, err)
+
+	// the stolen refresh token is used again, the whole family is revoked
+	_, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.True(t, IsRefreshTokenReused(err))
+
+	_, err = manager.Refresh(ctx, refreshed.RefreshToken, testLog)
+	assert.True(t, IsTokenRevoked(err))
+
+	for _, accessToken := range []string{pair.AccessToken, refreshed.AccessToken} {
+		_, _, err = manager.Resolve(ctx, accessToken, testLog)
+		assert.True(t, IsTokenRevoked(err))
+	}
+
+	// the other login is not affected
+	_, _, err = manager.Resolve(ctx, other.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	_, err = manager.Refresh(ctx, other.RefreshToken, testLog)
+	assert.NoError(t, err)
+}
+
+func TestTokenManager_Logout(t *testing.T) {
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	pair, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	claims, err := manager.GetJWT().Parse(pair.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	assert.NoError(t, manager.Logout(ctx, claims))
+
+	_, _, err = manager.Resolve(ctx, pair.AccessToken, testLog)
+	assert.True(t, IsTokenRevoked(err))
+
+	_, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.True(t, IsTokenRevoked(err))
+
+	// the token of NewUserJwtToken has no family, it's revoked by jti
+	token, _, err := NewUserJwtToken(2, nil, "secret")
+	assert.NoError(t, err)
+
+	claims, err = ParseJWTToken(token, "secret", testLog)
+	assert.NoError(t, err)
+
+	revoked, err := manager.IsRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, manager.Logout(ctx, claims))
+
+	revoked, err = manager.IsRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestTokenManager_RevokeUser(t *testing.T) {
+
+	ctx := context.Background()
+	manager := newTestTokenManager(t)
+
+	first, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	second, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	another, err := manager.Issue(ctx, 2, nil)
+	assert.NoError(t, err)
+
+	// the revocation is in seconds, the tokens issued in the same second are not revoked
+	time.Sleep(time.Second)
+	assert.NoError(t, manager.RevokeUser(ctx, 1))
+
+	for _, pair := range []*TokenPair{first, second} {
+
+		_, _, err = manager.Resolve(ctx, pair.AccessToken, testLog)
+		assert.True(t, IsTokenRevoked(err))
+
+		_, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+		assert.True(t, IsTokenRevoked(err))
+	}
+
+	_, _, err = manager.Resolve(ctx, another.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	// login again just after logging out everywhere
+	pair, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	_, _, err = manager.Resolve(ctx, pair.AccessToken, testLog)
+	assert.NoError(t, err)
+
+	_, err = manager.Refresh(ctx, pair.RefreshToken, testLog)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/idcreator/snowflake"
+)
+
+const (
+	// SessionClaim is the claim of access token, it's the session id of device class
+	SessionClaim = "sid"
+	// DeviceClaim is the claim of access token, it's the device class, e.g. mobile, web
+	DeviceClaim = "dev"
+)
+
+// Session is the active login of user on a device class, the new login replaces the previous one of the same device class.
+type Session struct {
+	Id        string            `json:"id"`
+	UserId    uint64            `json:"userId"`
+	Device    string            `json:"device"`
+	CreatedAt int64             `json:"createdAt"`
+	Metadata  map[string]string `json:"metadata,omitempty"` // e.g. device name, ip, shown in the list of sessions
+}
+
+// SessionChecker is used by authentication middlewares to reject the tokens of superseded sessions.
+type SessionChecker interface {
+	// CheckSession returns error checked by IsSessionSuperseded or IsTokenRevoked if the session isn't active,
+	// the tokens without session are passed.
+	CheckSession(ctx context.Context, claims jwt.MapClaims) error
+}
+
+// SessionManager enforces single session per user and device class, e.g. logging in on a new phone kicks out the previous phone,
+// while web and mobile sessions coexist. It's used by TokenManager with TokenManagerSessions.
+type SessionManager struct {
+	store SessionStore
+}
+
+func NewSessionManager(store SessionStore) *SessionManager {
+	return &SessionManager{store: store}
+}
+
+// Start creates the session of device class, the previous session of it is superseded.
+func (manager *SessionManager) Start(ctx context.Context, userId uint64, device string, metadata map[string]string, ttl time.Duration) (*Session, error) {
+
+	session := &Session{
+		Id:        strconv.FormatUint(snowflake.NextID(), 10),
+		UserId:    userId,
+		Device:    device,
+		CreatedAt: time.Now().Unix(),
+		Metadata:  metadata,
+	}
+
+	if err := manager.store.SetSession(ctx, session, ttl); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Extend keeps the session active for ttl, it's called when the tokens are refreshed.
+func (manager *SessionManager) Extend(ctx context.Context, userId uint64, device, sessionId string, ttl time.Duration) error {
+
+	extended, err := manager.store.ExtendSession(ctx, userId, device, sessionId, ttl)
+	if err != nil {
+		return err
+	}
+
+	if !extended {
+		return manager.getInactiveError(ctx, userId, device)
+	}
+
+	return nil
+}
+
+func (manager *SessionManager) CheckSession(ctx context.Context, claims jwt.MapClaims) error {
+
+	sessionId, _ := claims[SessionClaim].(string)
+	if sessionId == "" {
+		return nil
+	}
+
+	device, _ := claims[DeviceClaim].(string)
+	subject, _ := claims["sub"].(string)
+
+	userId, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return errorTokenInvalid
+	}
+
+	session, err := manager.store.GetSession(ctx, userId, device)
+	if err != nil {
+		return err
+	}
+
+	if session == nil {
+		return errorTokenRevoked
+	}
+
+	if session.Id != sessionId {
+		return errorSessionSuperseded
+	}
+
+	return nil
+}
+
+// List returns the active sessions of user.
+func (manager *SessionManager) List(ctx context.Context, userId uint64) ([]*Session, error) {
+	return manager.store.ListSessions(ctx, userId)
+}
+
+// Terminate kicks out the session of device class, its tokens are rejected as revoked.
+func (manager *SessionManager) Terminate(ctx context.Context, userId uint64, device string) error {
+
+	_, err := manager.store.DeleteSession(ctx, userId, device, "")
+	return err
+}
+
+func (manager *SessionManager) TerminateAll(ctx context.Context, userId uint64) error {
+
+	sessions, err := manager.store.ListSessions(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if _, err = manager.store.DeleteSession(ctx, userId, session.Device, session.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// End deletes the session of claims if it's still active, it's called when logout.
+func (manager *SessionManager) End(ctx context.Context, claims jwt.MapClaims) error {
+
+	sessionId, _ := claims[SessionClaim].(string)
+	if sessionId == "" {
+		return nil
+	}
+
+	device, _ := claims[DeviceClaim].(string)
+	subject, _ := claims["sub"].(string)
+
+	userId, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return errorTokenInvalid
+	}
+
+	_, err = manager.store.DeleteSession(ctx, userId, device, sessionId)
+	return err
+}
+
+// getInactiveError distinguishes the session superseded by another login from the terminated one
+func (manager *SessionManager) getInactiveError(ctx context.Context, userId uint64, device string) error {
+
+	session, err := manager.store.GetSession(ctx, userId, device)
+	if err != nil {
+		return err
+	}
+
+	if session == nil {
+		return errorTokenRevoked
+	}
+
+	return errorSessionSuperseded
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	dataCache "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+)
+
+// SessionStore saves the active session of every user and device class.
+type SessionStore interface {
+	// SetSession replaces the session of the same device class
+	SetSession(ctx context.Context, session *Session, ttl time.Duration) error
+	// GetSession returns nil without error if the session not exist
+	GetSession(ctx context.Context, userId uint64, device string) (*Session, error)
+	ListSessions(ctx context.Context, userId uint64) ([]*Session, error)
+	// ExtendSession resets ttl of the session if it's still active, it returns false if not
+	ExtendSession(ctx context.Context, userId uint64, device, sessionId string, ttl time.Duration) (bool, error)
+	// DeleteSession deletes the session if it's active, any session of the device class is deleted if session id is empty
+	DeleteSession(ctx context.Context, userId uint64, device, sessionId string) (bool, error)
+}
+
+// the session is compared by id before changed, so the new session of another login is not touched
+var extendSessionScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value or cjson.decode(value).id ~= ARGV[1] then
+	return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+redis.call("PEXPIRE", KEYS[2], ARGV[2])
+return 1
+`)
+
+var deleteSessionScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value or (ARGV[1] ~= "" and cjson.decode(value).id ~= ARGV[1]) then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+redis.call("SREM", KEYS[2], ARGV[2])
+return 1
+`)
+
+// RedisSessionStore saves sessions in the redis connection of data/cache pool,
+// every session is a key, and the device classes of user are a set.
+type RedisSessionStore struct {
+	connectionKey string
+	prefix        string
+}
+
+type RedisSessionStoreOption func(store *RedisSessionStore)
+
+func RedisSessionStorePrefix(prefix string) RedisSessionStoreOption {
+	return func(store *RedisSessionStore) {
+		store.prefix = prefix
+	}
+}
+
+func NewRedisSessionStore(connectionKey string, options ...RedisSessionStoreOption) *RedisSessionStore {
+
+	store := &RedisSessionStore{
+		connectionKey: connectionKey,
+		prefix:        DefaultTokenKeyPrefix,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+func (store *RedisSessionStore) SetSession(ctx context.Context, session *Session, ttl time.Duration) error {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	devicesKey := store.getDevicesKey(session.UserId)
+
+	pipe := conn.TxPipeline()
+	pipe.Set(ctx, store.getSessionKey(session.UserId, session.Device), value, ttl)
+	pipe.SAdd(ctx, devicesKey, session.Device)
+	pipe.Expire(ctx, devicesKey, ttl)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+func (store *RedisSessionStore) GetSession(ctx context.Context, userId uint64, device string) (*Session, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := conn.Get(ctx, store.getSessionKey(userId, device)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{}
+	if err = json.Unmarshal(value, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (store *RedisSessionStore) ListSessions(ctx context.Context, userId uint64) ([]*Session, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := conn.SMembers(ctx, store.getDevicesKey(userId)).Result()
+	if err != nil || len(devices) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(devices))
+	for _, device := range devices {
+		keys = append(keys, store.getSessionKey(userId, device))
+	}
+
+	values, err := conn.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(values))
+	for _, value := range values {
+
+		// the session expired
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		session := &Session{}
+		if err = json.Unmarshal([]byte(str), session); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (store *RedisSessionStore) ExtendSession(ctx context.Context, userId uint64, device, sessionId string, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{store.getSessionKey(userId, device), store.getDevicesKey(userId)}
+
+	extended, err := extendSessionScript.Run(ctx, conn.Client, keys, sessionId, ttl.Milliseconds()).Int()
+	return extended == 1, err
+}
+
+func (store *RedisSessionStore) DeleteSession(ctx context.Context, userId uint64, device, sessionId string) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{store.getSessionKey(userId, device), store.getDevicesKey(userId)}
+
+	deleted, err := deleteSessionScript.Run(ctx, conn.Client, keys, sessionId, device).Int()
+	return deleted == 1, err
+}
+
+func (store *RedisSessionStore) getSessionKey(userId uint64, device string) string {
+	return store.prefix + "session:" + strconv.FormatUint(userId, 10) + ":" + device
+}
+
+func (store *RedisSessionStore) getDevicesKey(userId uint64) string {
+	return store.prefix + "sessions:" + strconv.FormatUint(userId, 10)
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySessionStore struct {
+	lock     sync.Mutex
+	sessions map[string]*Session
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: map[string]*Session{}}
+}
+
+func (store *memorySessionStore) key(userId uint64, device string) string {
+	return strconv.FormatUint(userId, 10) + ":" + device
+}
+
+func (store *memorySessionStore) SetSession(ctx context.Context, session *Session, ttl time.Duration) error {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.sessions[store.key(session.UserId, session.Device)] = session
+	return nil
+}
+
+func (store *memorySessionStore) GetSession(ctx context.Context, userId uint64, device string) (*Session, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.sessions[store.key(userId, device)], nil
+}
+
+func (store *memorySessionStore) ListSessions(ctx context.Context, userId uint64) ([]*Session, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	var sessions []*Session
+	for _, session := range store.sessions {
+		if session.UserId == userId {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Device < sessions[j].Device })
+	return sessions, nil
+}
+
+func (store *memorySessionStore) ExtendSession(ctx context.Context, userId uint64, device, sessionId string, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	session := store.sessions[store.key(userId, device)]
+	return session != nil && session.Id == sessionId, nil
+}
+
+func (store *memorySessionStore) DeleteSession(ctx context.Context, userId uint64, device, sessionId string) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	key := store.key(userId, device)
+	session := store.sessions[key]
+	if session == nil || sessionId != "" && session.Id != sessionId {
+		return false, nil
+	}
+
+	delete(store.sessions, key)
+	return true, nil
+}
+
+func TestTokenManager_Sessions(t *testing.T) {
+
+	ctx := context.Background()
+
+	j, err := NewJWT(JWTKeys(NewHMACKey("hmac", []byte("secret"))))
+	assert.NoError(t, err)
+
+	sessions := NewSessionManager(newMemorySessionStore())
+	manager := NewTokenManager(j, newMemoryTokenStore(), TokenManagerSessions(sessions))
+
+	parse := func(pair *TokenPair) jwt.MapClaims {
+		claims, err := j.Parse(pair.AccessToken, testLog)
+		assert.NoError(t, err)
+		return claims
+	}
+
+	oldPhone, err := manager.Issue(ctx, 1, nil, IssueDevice("mobile", map[string]string{"name": "old phone"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "mobile", parse(oldPhone)[DeviceClaim])
+	assert.NotEmpty(t, parse(oldPhone)[SessionClaim])
+
+	web, err := manager.Issue(ctx, 1, nil, IssueDevice("web", nil))
+	assert.NoError(t, err)
+
+	newPhone, err := manager.IssueUserClaims(ctx, &Claims{UserId: 1}, IssueDevice("mobile", map[string]string{"name": "new phone"}))
+	assert.NoError(t, err)
+
+	other, err := manager.Issue(ctx, 2, nil, IssueDevice("mobile", nil))
+	assert.NoError(t, err)
+
+	withoutDevice, err := manager.Issue(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	// the new phone kicks out the old phone, while web and the other user are not affected
+	assert.True(t, IsSessionSuperseded(sessions.CheckSession(ctx, parse(oldPhone))))
+	assert.NoError(t, sessions.CheckSession(ctx, parse(newPhone)))
+	assert.NoError(t, sessions.CheckSession(ctx, parse(web)))
+	assert.NoError(t, sessions.CheckSession(ctx, parse(other)))
+	assert.NoError(t, sessions.CheckSession(ctx, parse(withoutDevice)))
+
+	_, err = manager.Refresh(ctx, oldPhone.RefreshToken, testLog)
+	assert.True(t, IsSessionSuperseded(err))
+
+	refreshed, err := manager.Refresh(ctx, newPhone.RefreshToken, testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, parse(newPhone)[SessionClaim], parse(refreshed)[SessionClaim])
+
+	list, err := sessions.List(ctx, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "mobile", list[0].Device)
+		assert.Equal(t, "new phone", list[0].Metadata["name"])
+		assert.Equal(t, "web", list[1].Device)
+	}
+
+	// the terminated session is revoked, not logged in elsewhere
+	assert.NoError(t, sessions.Terminate(ctx, 1, "web"))
+	assert.True(t, IsTokenRevoked(sessions.CheckSession(ctx, parse(web))))
+
+	_, err = manager.Refresh(ctx, web.RefreshToken, testLog)
+	assert.True(t, IsTokenRevoked(err))
+
+	// logout of the superseded session doesn't end the active one
+	assert.NoError(t, manager.Logout(ctx, parse(oldPhone)))
+	assert.NoError(t, sessions.CheckSession(ctx, parse(refreshed)))
+
+	assert.NoError(t, manager.Logout(ctx, parse(refreshed)))
+	assert.True(t, IsTokenRevoked(sessions.CheckSession(ctx, parse(refreshed))))
+
+	assert.NoError(t, sessions.TerminateAll(ctx, 2))
+	list, err = sessions.List(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
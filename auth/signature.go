@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers of HMAC request signature.
+const (
+	SignatureAccessKeyHeader = "X-Access-Key-Id"
+	SignatureTimestampHeader = "X-Timestamp" // unix seconds
+	SignatureNonceHeader     = "X-Nonce"
+	SignatureHeader          = "X-Signature" // hex of HMAC-SHA256
+)
+
+// StringToSign builds the canonical string of request:
+//
+//	METHOD\nPATH\nSORTED_QUERY\nHEX(SHA256(BODY))\nACCESS_KEY_ID\nTIMESTAMP\nNONCE
+//
+// the query is sorted by keys and values, and encoded as RFC 3986.
+func StringToSign(method, path string, query url.Values, body []byte, accessKeyId, timestamp, nonce string) string {
+
+	if path == "" {
+		path = "/"
+	}
+
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		canonicalQuery(query),
+		hex.EncodeToString(bodyHash[:]),
+		accessKeyId,
+		timestamp,
+		nonce,
+	}, "\n")
+}
+
+func canonicalQuery(query url.Values) string {
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(query))
+	for _, key := range keys {
+
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+
+		for _, value := range values {
+			pairs = append(pairs, escapeQuery(key)+"="+escapeQuery(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// escapeQuery escapes space as %20 instead of +
+func escapeQuery(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
+
+// Sign returns the hex of HMAC-SHA256 of content.
+func Sign(secret []byte, content string) string {
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(content))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signer signs the requests of net/http client by access key.
+type Signer struct {
+	accessKeyId string
+	secret      []byte
+}
+
+func NewSigner(accessKeyId string, secret []byte) *Signer {
+	return &Signer{accessKeyId: accessKeyId, secret: secret}
+}
+
+// Sign sets the signature headers of request, the body is read and restored.
+func (signer *Signer) Sign(request *http.Request) error {
+
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		request.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	content := StringToSign(request.Method, request.URL.EscapedPath(), request.URL.Query(), body, signer.accessKeyId, timestamp, nonce)
+
+	request.Header.Set(SignatureAccessKeyHeader, signer.accessKeyId)
+	request.Header.Set(SignatureTimestampHeader, timestamp)
+	request.Header.Set(SignatureNonceHeader, nonce)
+	request.Header.Set(SignatureHeader, Sign(signer.secret, content))
+
+	return nil
+}
+
+// Transport returns a http.RoundTripper signs every request, the default transport is used if base is nil, e.g.
+//
+//	client := &http.Client{Transport: auth.NewSigner(accessKeyId, secret).Transport(nil)}
+func (signer *Signer) Transport(base http.RoundTripper) http.RoundTripper {
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &signatureTransport{signer: signer, base: base}
+}
+
+type signatureTransport struct {
+	signer *Signer
+	base   http.RoundTripper
+}
+
+func (transport *signatureTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	// RoundTripper should not modify the request
+	request = request.Clone(request.Context())
+
+	if err := transport.signer.Sign(request); err != nil {
+		return nil, err
+	}
+
+	return transport.base.RoundTrip(request)
+}
+
+func newNonce() (string, error) {
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
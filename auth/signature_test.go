@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryNonceStore struct {
+	lock   sync.Mutex
+	nonces map[string]bool
+}
+
+func (store *memoryNonceStore) Use(ctx context.Context, accessKeyId, nonce string, ttl time.Duration) (bool, error) {
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	key := accessKeyId + ":" + nonce
+	if store.nonces[key] {
+		return false, nil
+	}
+
+	store.nonces[key] = true
+	return true, nil
+}
+
+func TestStringToSign(t *testing.T) {
+
+	query := url.Values{"b": {"2", "1"}, "a": {"x y"}}
+
+	actual := StringToSign("post", "/v1/orders", query, []byte(""), "key", "1600000000", "abc")
+
+	assert.Equal(t, "POST\n/v1/orders\na=x%20y&b=1&b=2\n"+
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\nkey\n1600000000\nabc", actual)
+}
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+
+	verifier := NewSignatureVerifier(StaticSecrets{"partner": "secret"},
+		SignatureNonceStore(&memoryNonceStore{nonces: map[string]bool{}}), SignatureSkew(time.Minute))
+
+	var accessKeyId string
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		accessKeyId, verifyErr = verifier.Verify(r, body)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewSigner("partner", []byte("secret")).Transport(nil)}
+
+	resp, err := client.Post(server.URL+"/callback?b=2&a=1", "application/json", strings.NewReader(`{"orderId":1}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "partner", accessKeyId)
+
+	resp, err = client.Get(server.URL + "/callback")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.NoError(t, verifyErr)
+
+	signed := func(secret string, modify func(request *http.Request)) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/callback?a=1", strings.NewReader("body"))
+		assert.NoError(t, NewSigner("partner", []byte(secret)).Sign(request))
+		if modify != nil {
+			modify(request)
+		}
+		return request
+	}
+
+	replayed := signed("secret", nil)
+	_, err = verifier.Verify(replayed, []byte("body"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		request *http.Request
+		body    string
+		check   func(err error) bool
+	}{
+		{request: replayed, body: "body", check: IsNonceReused},
+		{request: signed("secret", nil), body: "tampered", check: IsSignatureInvalid},
+		{request: signed("wrong", nil), body: "body", check: IsSignatureInvalid},
+		{request: signed("secret", func(request *http.Request) { request.URL.RawQuery = "a=2" }), body: "body", check: IsSignatureInvalid},
+		{request: signed("secret", func(request *http.Request) { request.Header.Set(SignatureAccessKeyHeader, "unknown") }), body: "body", check: IsSignatureInvalid},
+		{request: signed("secret", func(request *http.Request) { request.Header.Del(SignatureNonceHeader) }), body: "body", check: IsSignatureMissing},
+		{request: signed("secret", func(request *http.Request) { request.Header.Set(SignatureTimestampHeader, "1600000000") }), body: "body", check: IsSignatureExpired},
+	}
+
+	for index, test := range tests {
+		_, err := verifier.Verify(test.request, []byte(test.body))
+		assert.True(t, test.check(err), index, err)
+	}
+}
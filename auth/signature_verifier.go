@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dataCache "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+)
+
+const (
+	DefaultSignatureSkew  = 5 * time.Minute
+	DefaultNonceKeyPrefix = "signature:nonce:"
+)
+
+// SecretProvider looks up the secret of access key, it returns nil without error if the access key not exist.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, accessKeyId string) ([]byte, error)
+}
+
+// StaticSecrets is the secrets of access keys in memory, e.g. from configuration.
+type StaticSecrets map[string]string
+
+func (secrets StaticSecrets) GetSecret(ctx context.Context, accessKeyId string) ([]byte, error) {
+
+	secret, ok := secrets[accessKeyId]
+	if !ok {
+		return nil, nil
+	}
+
+	return []byte(secret), nil
+}
+
+// NonceStore records the used nonces, Use returns false if the nonce has been used in ttl.
+type NonceStore interface {
+	Use(ctx context.Context, accessKeyId, nonce string, ttl time.Duration) (bool, error)
+}
+
+// RedisNonceStore saves nonces in the redis connection of data/cache pool.
+type RedisNonceStore struct {
+	connectionKey string
+	prefix        string
+}
+
+type RedisNonceStoreOption func(store *RedisNonceStore)
+
+func RedisNonceStorePrefix(prefix string) RedisNonceStoreOption {
+	return func(store *RedisNonceStore) {
+		store.prefix = prefix
+	}
+}
+
+func NewRedisNonceStore(connectionKey string, options ...RedisNonceStoreOption) *RedisNonceStore {
+
+	store := &RedisNonceStore{
+		connectionKey: connectionKey,
+		prefix:        DefaultNonceKeyPrefix,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+func (store *RedisNonceStore) Use(ctx context.Context, accessKeyId, nonce string, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	return conn.SetNX(ctx, store.prefix+accessKeyId+":"+nonce, 1, ttl).Result()
+}
+
+// SignatureVerifier verifies the requests signed by Signer.
+type SignatureVerifier struct {
+	secrets    SecretProvider
+	nonceStore NonceStore
+	skew       time.Duration
+	now        func() time.Time
+}
+
+type SignatureVerifierOption func(verifier *SignatureVerifier)
+
+// SignatureNonceStore enables the replay protection, the nonce is kept for twice of skew,
+// the requests out of skew are rejected by timestamp, so the nonce can't be replayed.
+func SignatureNonceStore(store NonceStore) SignatureVerifierOption {
+	return func(verifier *SignatureVerifier) {
+		verifier.nonceStore = store
+	}
+}
+
+// SignatureSkew is the allowed difference between request timestamp and server time, default is 5 minutes.
+func SignatureSkew(skew time.Duration) SignatureVerifierOption {
+	return func(verifier *SignatureVerifier) {
+		verifier.skew = skew
+	}
+}
+
+func NewSignatureVerifier(secrets SecretProvider, options ...SignatureVerifierOption) *SignatureVerifier {
+
+	verifier := &SignatureVerifier{
+		secrets: secrets,
+		skew:    DefaultSignatureSkew,
+		now:     time.Now,
+	}
+
+	for _, option := range options {
+		option(verifier)
+	}
+
+	return verifier
+}
+
+// Verify checks the signature of request with the body read by caller, it returns the access key id if passed.
+// The errors except IsSignatureMissing, IsSignatureInvalid, IsSignatureExpired and IsNonceReused are from secret provider or nonce store.
+func (verifier *SignatureVerifier) Verify(request *http.Request, body []byte) (string, error) {
+
+	accessKeyId := request.Header.Get(SignatureAccessKeyHeader)
+	timestamp := request.Header.Get(SignatureTimestampHeader)
+	nonce := request.Header.Get(SignatureNonceHeader)
+	signature := request.Header.Get(SignatureHeader)
+
+	if accessKeyId == "" || timestamp == "" || nonce == "" || signature == "" {
+		return accessKeyId, errorSignatureMissing
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return accessKeyId, errorSignatureInvalid
+	}
+
+	diff := verifier.now().Sub(time.Unix(seconds, 0))
+	if diff > verifier.skew || diff < -verifier.skew {
+		return accessKeyId, errorSignatureExpired
+	}
+
+	ctx := request.Context()
+
+	secret, err := verifier.secrets.GetSecret(ctx, accessKeyId)
+	if err != nil {
+		return accessKeyId, err
+	}
+
+	if len(secret) == 0 {
+		return accessKeyId, errorSignatureInvalid
+	}
+
+	content := StringToSign(request.Method, request.URL.EscapedPath(), request.URL.Query(), body, accessKeyId, timestamp, nonce)
+	if !hmac.Equal([]byte(Sign(secret, content)), []byte(strings.ToLower(signature))) {
+		return accessKeyId, errorSignatureInvalid
+	}
+
+	// 签名通过后再记录 nonce, 避免伪造的请求占用 nonce
+	if verifier.nonceStore != nil {
+
+		unused, err := verifier.nonceStore.Use(ctx, accessKeyId, nonce, 2*verifier.skew)
+		if err != nil {
+			return accessKeyId, err
+		}
+
+		if !unused {
+			return accessKeyId, errorNonceReused
+		}
+	}
+
+	return accessKeyId, nil
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	dataCache "dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/data/cache"
+)
+
+const DefaultTokenKeyPrefix = "auth:"
+
+// RefreshTokenRecord is saved by the hash of refresh token, the tokens rotated from the same login are a family.
+type RefreshTokenRecord struct {
+	UserId         uint64                 `json:"userId"`
+	FamilyId       string                 `json:"familyId"`
+	FamilyIssuedAt int64                  `json:"familyIssuedAt"`
+	Info           map[string]interface{} `json:"info,omitempty"`
+	Claims         map[string]interface{} `json:"claims,omitempty"` // custom claims of UserClaims
+	ExpireAt       int64                  `json:"expireAt"`
+	SessionId      string                 `json:"sessionId,omitempty"`
+	Device         string                 `json:"device,omitempty"`
+}
+
+// TokenStore saves refresh tokens and revocations.
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, id string, record *RefreshTokenRecord, ttl time.Duration) error
+	// GetRefreshToken returns nil without error if the token not exist
+	GetRefreshToken(ctx context.Context, id string) (*RefreshTokenRecord, error)
+	// UseRefreshToken marks the token used, it returns false if the token has been used
+	UseRefreshToken(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	SetRevocation(ctx context.Context, key string, revokedAt int64, ttl time.Duration) error
+	// GetRevocations returns the revoked time of keys, it's 0 if the key is not revoked
+	GetRevocations(ctx context.Context, keys ...string) ([]int64, error)
+}
+
+// RedisTokenStore saves tokens in the redis connection of data/cache pool.
+type RedisTokenStore struct {
+	connectionKey string
+	prefix        string
+}
+
+type RedisTokenStoreOption func(store *RedisTokenStore)
+
+func RedisTokenStorePrefix(prefix string) RedisTokenStoreOption {
+	return func(store *RedisTokenStore) {
+		store.prefix = prefix
+	}
+}
+
+func NewRedisTokenStore(connectionKey string, options ...RedisTokenStoreOption) *RedisTokenStore {
+
+	store := &RedisTokenStore{
+		connectionKey: connectionKey,
+		prefix:        DefaultTokenKeyPrefix,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+func (store *RedisTokenStore) SaveRefreshToken(ctx context.Context, id string, record *RefreshTokenRecord, ttl time.Duration) error {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return conn.Set(ctx, store.prefix+"refresh:"+id, value, ttl).Err()
+}
+
+func (store *RedisTokenStore) GetRefreshToken(ctx context.Context, id string) (*RefreshTokenRecord, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := conn.Get(ctx, store.prefix+"refresh:"+id).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the custom claims may have ids above 2^53, they are decoded as json.Number
+	record := &RefreshTokenRecord{}
+	if err = decodeJSON(value, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (store *RedisTokenStore) UseRefreshToken(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return false, err
+	}
+
+	return conn.SetNX(ctx, store.prefix+"used:"+id, time.Now().Unix(), ttl).Result()
+}
+
+func (store *RedisTokenStore) SetRevocation(ctx context.Context, key string, revokedAt int64, ttl time.Duration) error {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return err
+	}
+
+	return conn.Set(ctx, store.prefix+"revoked:"+key, revokedAt, ttl).Err()
+}
+
+func (store *RedisTokenStore) GetRevocations(ctx context.Context, keys ...string) ([]int64, error) {
+
+	conn, err := dataCache.Get(store.connectionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	redisKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, store.prefix+"revoked:"+key)
+	}
+
+	values, err := conn.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := make([]int64, len(keys))
+	for index, value := range values {
+		if str, ok := value.(string); ok {
+			revocations[index], _ = strconv.ParseInt(str, 10, 64)
+		}
+	}
+
+	return revocations, nil
+}
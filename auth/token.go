@@ -0,0 +1,417 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/shomali11/util/xstrings"
+	"github.com/sirupsen/logrus"
+
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/utils/idcreator/snowflake"
+)
+
+const (
+	DefaultIssuer   = "wanxin"
+	DefaultLifetime = 24 * time.Hour
+)
+
+var errorNoSigningKey = errors.New("no signing key")
+
+// JWT issues and verifies tokens, the tokens are signed by the signing key, and verified by the key of kid header,
+// so the keys can be rotated by adding the new key as signing key, and removing the old key after its tokens expired.
+type JWT struct {
+	issuer   string
+	audience []string
+	lifetime time.Duration
+	leeway   time.Duration
+
+	lock         sync.RWMutex
+	keys         map[string]*Key
+	signingKeyId string
+}
+
+type JWTOption func(j *JWT)
+
+// JWTIssuer sets iss of issued tokens, and the tokens of other issuers are invalid if it's not empty.
+func JWTIssuer(issuer string) JWTOption {
+	return func(j *JWT) {
+		j.issuer = issuer
+	}
+}
+
+// JWTAudience sets aud of issued tokens, and the verified tokens must have one of them if it's not empty.
+func JWTAudience(audience ...string) JWTOption {
+	return func(j *JWT) {
+		j.audience = audience
+	}
+}
+
+func JWTLifetime(lifetime time.Duration) JWTOption {
+	return func(j *JWT) {
+		j.lifetime = lifetime
+	}
+}
+
+// JWTLeeway allows the clock skew between servers when checking exp and nbf.
+func JWTLeeway(leeway time.Duration) JWTOption {
+	return func(j *JWT) {
+		j.leeway = leeway
+	}
+}
+
+// JWTKeys adds keys, the first key can sign is the signing key if JWTSigningKey is not set.
+func JWTKeys(keys ...*Key) JWTOption {
+	return func(j *JWT) {
+		for _, key := range keys {
+			j.keys[key.id] = key
+			if j.signingKeyId == "" && key.CanSign() {
+				j.signingKeyId = key.id
+			}
+		}
+	}
+}
+
+func JWTSigningKey(id string) JWTOption {
+	return func(j *JWT) {
+		j.signingKeyId = id
+	}
+}
+
+func NewJWT(options ...JWTOption) (*JWT, error) {
+
+	j := &JWT{
+		issuer:   DefaultIssuer,
+		lifetime: DefaultLifetime,
+		keys:     make(map[string]*Key),
+	}
+
+	for _, option := range options {
+		option(j)
+	}
+
+	if len(j.keys) == 0 {
+		return nil, errors.New("jwt has no key")
+	}
+
+	key, exist := j.keys[j.signingKeyId]
+	if !exist && j.signingKeyId != "" {
+		return nil, fmt.Errorf("signing key %s not exist", j.signingKeyId)
+	}
+
+	if exist && !key.CanSign() {
+		return nil, fmt.Errorf("signing key %s: %w", j.signingKeyId, errorKeyCannotSign)
+	}
+
+	return j, nil
+}
+
+func (j *JWT) GetIssuer() string {
+	return j.issuer
+}
+
+func (j *JWT) GetLifetime() time.Duration {
+	return j.lifetime
+}
+
+// AddKey adds or replaces the key of same id.
+func (j *JWT) AddKey(key *Key) {
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.keys[key.id] = key
+}
+
+// RemoveKey removes the key, the tokens signed by it become invalid, the signing key can't be removed.
+func (j *JWT) RemoveKey(id string) error {
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if id == j.signingKeyId {
+		return fmt.Errorf("key %s is signing key", id)
+	}
+
+	delete(j.keys, id)
+	return nil
+}
+
+// SetSigningKey changes the key signs new tokens, the key must be added before.
+func (j *JWT) SetSigningKey(id string) error {
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	key, exist := j.keys[id]
+	if !exist {
+		return fmt.Errorf("key %s not exist", id)
+	}
+
+	if !key.CanSign() {
+		return fmt.Errorf("key %s: %w", id, errorKeyCannotSign)
+	}
+
+	j.signingKeyId = id
+	return nil
+}
+
+// JWKS returns the public keys, it's responded by the jwks endpoint for other services verifying tokens.
+func (j *JWT) JWKS() JWKS {
+
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(j.keys))}
+	for _, key := range j.keys {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
+
+// Issue returns token of user, the info is saved in info claim as json.
+func (j *JWT) Issue(userId uint64, info map[string]interface{}) (string, int64, error) {
+	return j.IssueClaims(userId, info, nil)
+}
+
+// IssueClaims issues token with custom claims, the registered claims (jti, iss, aud, sub, iat, nbf, exp) are set by JWT.
+func (j *JWT) IssueClaims(userId uint64, info map[string]interface{}, custom jwt.MapClaims) (string, int64, error) {
+
+	j.lock.RLock()
+	key, exist := j.keys[j.signingKeyId]
+	j.lock.RUnlock()
+
+	if !exist || !key.CanSign() {
+		return "", 0, errorNoSigningKey
+	}
+
+	now := time.Now()
+	expireAt := now.Add(j.lifetime).Unix()
+
+	claims := make(jwt.MapClaims, len(custom)+8)
+	for name, value := range custom {
+		claims[name] = value
+	}
+
+	claims["jti"] = strconv.FormatUint(snowflake.NextID(), 10)
+	claims["sub"] = strconv.FormatUint(userId, 10)
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = expireAt
+
+	if j.issuer != "" {
+		claims["iss"] = j.issuer
+	}
+
+	if len(j.audience) == 1 {
+		claims["aud"] = j.audience[0]
+	} else if len(j.audience) > 1 {
+		claims["aud"] = j.audience
+	}
+
+	if info != nil {
+		infoBytes, err := json.Marshal(info)
+		if err != nil {
+			return "", 0, err
+		}
+		claims["info"] = string(infoBytes)
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+
+	signedToken, err := token.SignedString(key.signKey)
+	return signedToken, expireAt, err
+}
+
+// Parse verifies the token, and returns the claims.
+// The error is checked by IsTokenExpired and IsTokenInvalid.
+func (j *JWT) Parse(tokenString string, log *logrus.Entry) (jwt.MapClaims, error) {
+
+	if xstrings.IsBlank(tokenString) {
+		return nil, errorEmptyString
+	}
+
+	log = log.WithField("token", RedactToken(tokenString))
+
+	// claims are validated by validateClaims with leeway, numbers are json.Number to keep the ids above 2^53
+	parser := &jwt.Parser{SkipClaimsValidation: true, UseJSONNumber: true}
+
+	claims := jwt.MapClaims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, j.getVerifyKey)
+	if err != nil {
+		log.WithError(err).Warn("token is invalid")
+		return nil, errorTokenInvalid
+	}
+
+	if !token.Valid {
+		log.Warn("token is invalid")
+		return nil, errorTokenInvalid
+	}
+
+	if err = j.validateClaims(claims); err != nil {
+		if err != errorTokenExpired {
+			log.WithError(err).Warn("token claims are invalid")
+			return nil, errorTokenInvalid
+		}
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Resolve verifies the token, and returns user id and info.
+func (j *JWT) Resolve(tokenString string, log *logrus.Entry) (userId uint64, info map[string]interface{}, err error) {
+
+	claims, err := j.Parse(tokenString, log)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return ResolveClaims(claims, log.WithField("token", RedactToken(tokenString)))
+}
+
+func (j *JWT) getVerifyKey(token *jwt.Token) (interface{}, error) {
+
+	kid, _ := token.Header["kid"].(string)
+
+	j.lock.RLock()
+	key, exist := j.keys[kid]
+	if !exist && kid == "" {
+		key, exist = j.keys[j.signingKeyId]
+	}
+	j.lock.RUnlock()
+
+	if !exist {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	if !isSameMethod(key.method, token.Method) {
+		return nil, fmt.Errorf("unexpected sign method: %v", token.Header["alg"])
+	}
+
+	return key.verifyKey, nil
+}
+
+// isSameMethod prevents the algorithm confusion, e.g. verifying HS256 token by the RSA public key as HMAC secret.
+// The HMAC key verifies HS384 and HS512 as before.
+func isSameMethod(expected, actual jwt.SigningMethod) bool {
+
+	if _, ok := expected.(*jwt.SigningMethodHMAC); ok {
+		_, ok = actual.(*jwt.SigningMethodHMAC)
+		return ok
+	}
+
+	return expected.Alg() == actual.Alg()
+}
+
+func (j *JWT) validateClaims(claims jwt.MapClaims) error {
+
+	now := time.Now().Unix()
+	leeway := int64(j.leeway / time.Second)
+
+	if !claims.VerifyExpiresAt(now-leeway, false) {
+		return errorTokenExpired
+	}
+
+	if !claims.VerifyNotBefore(now+leeway, false) {
+		return errors.New("token is not valid yet")
+	}
+
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return fmt.Errorf("unexpected issuer: %v", claims["iss"])
+	}
+
+	if len(j.audience) > 0 && !verifyAudience(claims["aud"], j.audience) {
+		return fmt.Errorf("unexpected audience: %v", claims["aud"])
+	}
+
+	return nil
+}
+
+// verifyAudience accepts aud of string or array, jwt-go v3 only supports string
+func verifyAudience(aud interface{}, expected []string) bool {
+
+	var audiences []string
+	switch aud := aud.(type) {
+	case string:
+		audiences = []string{aud}
+	case []interface{}:
+		for _, item := range aud {
+			if audience, ok := item.(string); ok {
+				audiences = append(audiences, audience)
+			}
+		}
+	}
+
+	for _, audience := range audiences {
+		for _, expectedAudience := range expected {
+			if audience == expectedAudience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ResolveClaims returns user id of sub claim and user information of info claim.
+func ResolveClaims(claims jwt.MapClaims, log *logrus.Entry) (userId uint64, info map[string]interface{}, err error) {
+
+	subject, ok := claims["sub"]
+
+	if !ok {
+		log.Warn("subject not exist")
+		return 0, nil, errorTokenInvalid
+	}
+
+	subjectStr, ok := subject.(string)
+
+	if !ok {
+		log.Warn("subject not a string")
+		return 0, nil, errorTokenInvalid
+	}
+
+	userId, err = strconv.ParseUint(subjectStr, 10, 64)
+
+	if err != nil {
+		log.WithError(err).Warn("parse userId error")
+		return 0, nil, errorTokenInvalid
+	}
+
+	if infoJSON, ok := claims["info"]; ok {
+
+		if infoJSONString, ok := infoJSON.(string); ok {
+
+			err := json.Unmarshal([]byte(infoJSONString), &info)
+			if err != nil {
+				log.WithField("infoJSON", infoJSONString).WithError(err).Warn("unmarshal info json error")
+			}
+		}
+	}
+
+	return userId, info, nil
+}
+
+// RedactToken returns the fingerprint of token for logging, the same token has the same fingerprint,
+// so the requests can be correlated without leaking the token.
+func RedactToken(tokenString string) string {
+
+	if tokenString == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(tokenString))
+	return "sha256:" + hex.EncodeToString(hash[:6])
+}
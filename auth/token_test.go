@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLog = logrus.NewEntry(logrus.StandardLogger())
+
+func newTestKeys(t *testing.T) map[string]*Key {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	return map[string]*Key{
+		AlgorithmHS256: NewHMACKey("hmac", []byte("secret")),
+		AlgorithmRS256: NewRSAKey("rsa", rsaKey),
+		AlgorithmES256: NewECDSAKey("ecdsa", ecdsaKey),
+		AlgorithmEdDSA: NewEd25519Key("ed25519", ed25519Key),
+	}
+}
+
+func TestJWT_Algorithms(t *testing.T) {
+
+	for algorithm, key := range newTestKeys(t) {
+
+		j, err := NewJWT(JWTKeys(key), JWTAudience("app"))
+		assert.NoError(t, err)
+
+		token, expireAt, err := j.Issue(1, map[string]interface{}{"name": "test"})
+		assert.NoError(t, err, algorithm)
+		assert.InDelta(t, time.Now().Add(DefaultLifetime).Unix(), expireAt, 2)
+
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+		assert.NoError(t, err)
+		assert.Equal(t, algorithm, parsed.Header["alg"])
+		assert.Equal(t, key.GetId(), parsed.Header["kid"])
+
+		userId, info, err := j.Resolve(token, testLog)
+		assert.NoError(t, err, algorithm)
+		assert.Equal(t, uint64(1), userId)
+		assert.Equal(t, "test", info["name"])
+	}
+}
+
+func TestJWT_Validation(t *testing.T) {
+
+	key := NewHMACKey("hmac", []byte("secret"))
+
+	issue := func(options ...JWTOption) string {
+		j, err := NewJWT(append([]JWTOption{JWTKeys(key)}, options...)...)
+		assert.NoError(t, err)
+		token, _, err := j.Issue(1, nil)
+		assert.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		token   string
+		options []JWTOption
+		err     error
+	}{
+		{token: issue(), err: nil},
+		{token: issue(JWTLifetime(-time.Second)), err: errorTokenExpired},
+		{token: issue(JWTLifetime(-time.Second)), options: []JWTOption{JWTLeeway(time.Minute)}, err: nil},
+		{token: issue(JWTIssuer("other")), err: errorTokenInvalid},
+		{token: issue(JWTIssuer("other")), options: []JWTOption{JWTIssuer("")}, err: nil},
+		{token: issue(JWTAudience("a", "b")), options: []JWTOption{JWTAudience("b")}, err: nil},
+		{token: issue(JWTAudience("a")), options: []JWTOption{JWTAudience("b")}, err: errorTokenInvalid},
+		{token: issue(), options: []JWTOption{JWTAudience("b")}, err: errorTokenInvalid},
+		{token: "", err: errorEmptyString},
+		{token: "invalid", err: errorTokenInvalid},
+	}
+
+	for index, test := range tests {
+
+		j, err := NewJWT(append([]JWTOption{JWTKeys(key)}, test.options...)...)
+		assert.NoError(t, err)
+
+		_, err = j.Parse(test.token, testLog)
+		assert.Equal(t, test.err, err, index)
+	}
+}
+
+func TestJWT_Rotation(t *testing.T) {
+
+	keys := newTestKeys(t)
+	oldKey, newKey := keys[AlgorithmRS256], keys[AlgorithmEdDSA]
+
+	j, err := NewJWT(JWTKeys(oldKey))
+	assert.NoError(t, err)
+
+	oldToken, _, err := j.Issue(1, nil)
+	assert.NoError(t, err)
+
+	j.AddKey(newKey)
+	assert.NoError(t, j.SetSigningKey(newKey.GetId()))
+	assert.Error(t, j.RemoveKey(newKey.GetId()))
+
+	newToken, _, err := j.Issue(2, nil)
+	assert.NoError(t, err)
+
+	userId, _, err := j.Resolve(oldToken, testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userId)
+
+	userId, _, err = j.Resolve(newToken, testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), userId)
+
+	assert.Len(t, j.JWKS().Keys, 2)
+
+	assert.NoError(t, j.RemoveKey(oldKey.GetId()))
+	_, _, err = j.Resolve(oldToken, testLog)
+	assert.Equal(t, errorTokenInvalid, err)
+
+	// verify only key can't sign
+	verifier, err := NewJWT(JWTKeys(NewEd25519PublicKey(newKey.GetId(), newKey.verifyKey.(ed25519.PublicKey))))
+	assert.NoError(t, err)
+
+	_, _, err = verifier.Issue(1, nil)
+	assert.Equal(t, errorNoSigningKey, err)
+
+	_, _, err = verifier.Resolve(newToken, testLog)
+	assert.NoError(t, err)
+	assert.Error(t, verifier.SetSigningKey(newKey.GetId()))
+}
+
+func TestNewJWT_SigningKey(t *testing.T) {
+
+	keys := newTestKeys(t)
+	rsaKey, edKey := keys[AlgorithmRS256], keys[AlgorithmEdDSA]
+	publicKey := NewEd25519PublicKey("public", edKey.verifyKey.(ed25519.PublicKey))
+
+	tests := []struct {
+		options      []JWTOption
+		signingKeyId string
+		err          bool
+	}{
+		{options: []JWTOption{JWTKeys(rsaKey, edKey)}, signingKeyId: rsaKey.GetId()},
+		{options: []JWTOption{JWTKeys(rsaKey, edKey), JWTSigningKey(edKey.GetId())}, signingKeyId: edKey.GetId()},
+		{options: []JWTOption{JWTSigningKey(edKey.GetId()), JWTKeys(rsaKey, edKey)}, signingKeyId: edKey.GetId()},
+		{options: []JWTOption{JWTKeys(rsaKey), JWTSigningKey("missing")}, err: true},
+		{options: []JWTOption{JWTKeys(publicKey), JWTSigningKey("public")}, err: true},
+		{options: []JWTOption{JWTKeys(publicKey)}},
+		{options: []JWTOption{JWTSigningKey(rsaKey.GetId())}, err: true},
+	}
+
+	for index, test := range tests {
+
+		j, err := NewJWT(test.options...)
+		if test.err {
+			assert.Error(t, err, index)
+			continue
+		}
+
+		assert.NoError(t, err, index)
+		assert.Equal(t, test.signingKeyId, j.signingKeyId, index)
+	}
+}
+
+func TestJWT_AlgorithmConfusion(t *testing.T) {
+
+	rsaKey := newTestKeys(t)[AlgorithmRS256]
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(rsaKey.verifyKey)
+	assert.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+
+	// the token signed by the public key as HMAC secret
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1", "iss": DefaultIssuer})
+	token.Header["kid"] = rsaKey.GetId()
+	tokenString, err := token.SignedString(publicKeyPEM)
+	assert.NoError(t, err)
+
+	j, err := NewJWT(JWTKeys(rsaKey))
+	assert.NoError(t, err)
+
+	_, err = j.Parse(tokenString, testLog)
+	assert.Equal(t, errorTokenInvalid, err)
+}
+
+func TestJWKS(t *testing.T) {
+
+	keys := newTestKeys(t)
+
+	j, err := NewJWT(JWTKeys(keys[AlgorithmHS256], keys[AlgorithmRS256], keys[AlgorithmES256], keys[AlgorithmEdDSA]))
+	assert.NoError(t, err)
+
+	jwks := map[string]JWK{}
+	for _, jwk := range j.JWKS().Keys {
+		jwks[jwk.KeyId] = jwk
+	}
+
+	assert.Len(t, jwks, 3)
+	assert.Equal(t, JWK{KeyType: "RSA", KeyId: "rsa", Use: "sig", Algorithm: "RS256",
+		N: jwks["rsa"].N, E: "AQAB"}, jwks["rsa"])
+	assert.Equal(t, "P-256", jwks["ecdsa"].Curve)
+	assert.Len(t, jwks["ecdsa"].X, 43)
+	assert.Len(t, jwks["ecdsa"].Y, 43)
+	assert.Equal(t, "OKP", jwks["ed25519"].KeyType)
+	assert.Equal(t, "Ed25519", jwks["ed25519"].Curve)
+	assert.Equal(t, "EdDSA", jwks["ed25519"].Algorithm)
+}
+
+func TestLoadKeyFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "jwt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	keys := newTestKeys(t)
+
+	write := func(name string, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+		return path
+	}
+
+	rsaPrivate := x509.MarshalPKCS1PrivateKey(keys[AlgorithmRS256].signKey.(*rsa.PrivateKey))
+	rsaPublic, _ := x509.MarshalPKIXPublicKey(keys[AlgorithmRS256].verifyKey)
+	ecdsaPrivate, _ := x509.MarshalECPrivateKey(keys[AlgorithmES256].signKey.(*ecdsa.PrivateKey))
+	ecdsaPublic, _ := x509.MarshalPKIXPublicKey(keys[AlgorithmES256].verifyKey)
+	ed25519Private, _ := x509.MarshalPKCS8PrivateKey(keys[AlgorithmEdDSA].signKey)
+	ed25519Public, _ := x509.MarshalPKIXPublicKey(keys[AlgorithmEdDSA].verifyKey)
+
+	secretPath := filepath.Join(dir, "secret")
+	assert.NoError(t, ioutil.WriteFile(secretPath, []byte("secret\n"), 0600))
+
+	tests := []struct {
+		algorithm string
+		path      string
+		canSign   bool
+	}{
+		{algorithm: AlgorithmHS256, path: secretPath, canSign: true},
+		{algorithm: AlgorithmRS256, path: write("rsa.pem", "RSA PRIVATE KEY", rsaPrivate), canSign: true},
+		{algorithm: AlgorithmRS256, path: write("rsa.pub.pem", "PUBLIC KEY", rsaPublic), canSign: false},
+		{algorithm: AlgorithmES256, path: write("ecdsa.pem", "EC PRIVATE KEY", ecdsaPrivate), canSign: true},
+		{algorithm: AlgorithmES256, path: write("ecdsa.pub.pem", "PUBLIC KEY", ecdsaPublic), canSign: false},
+		{algorithm: AlgorithmEdDSA, path: write("ed25519.pem", "PRIVATE KEY", ed25519Private), canSign: true},
+		{algorithm: AlgorithmEdDSA, path: write("ed25519.pub.pem", "PUBLIC KEY", ed25519Public), canSign: false},
+	}
+
+	for _, test := range tests {
+
+		key, err := LoadKeyFile("id", test.algorithm, test.path)
+		assert.NoError(t, err, test.path)
+		assert.Equal(t, test.algorithm, key.GetAlgorithm())
+		assert.Equal(t, test.canSign, key.CanSign(), test.path)
+	}
+
+	_, err = LoadKeyFile("id", AlgorithmEdDSA, filepath.Join(dir, "rsa.pem"))
+	assert.Error(t, err)
+
+	_, err = LoadKeyFile("id", "HS512", secretPath)
+	assert.Error(t, err)
+
+	_, err = LoadKeyFile("id", AlgorithmHS256, filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestResolveJWTToken(t *testing.T) {
+
+	token, _, err := NewUserJwtToken(1, map[string]interface{}{"name": "test"}, "secret")
+	assert.NoError(t, err)
+
+	userId, info, err := ResolveJWTToken(token, "secret", testLog)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userId)
+	assert.Equal(t, "test", info["name"])
+
+	_, _, err = ResolveJWTToken(token, "other", testLog)
+	assert.True(t, IsTokenInvalid(err))
+
+	// tokens of other issuers are accepted as before
+	j, err := NewJWT(JWTKeys(NewHMACKey("", []byte("secret"))), JWTIssuer("other"), JWTLifetime(-time.Minute))
+	assert.NoError(t, err)
+	token, _, err = j.Issue(1, nil)
+	assert.NoError(t, err)
+
+	_, _, err = ResolveJWTToken(token, "secret", testLog)
+	assert.True(t, IsTokenExpired(err))
+}
+
+func TestRedactToken(t *testing.T) {
+
+	redacted := RedactToken("eyJhbGciOiJIUzI1NiJ9.eyJ1c2VySWQiOjF9.signature")
+
+	assert.Equal(t, "", RedactToken(""))
+	assert.Len(t, redacted, len("sha256:")+12)
+	assert.NotContains(t, redacted, "eyJ")
+	assert.Equal(t, redacted, RedactToken("eyJhbGciOiJIUzI1NiJ9.eyJ1c2VySWQiOjF9.signature"))
+}
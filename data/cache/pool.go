@@ -1,29 +1,66 @@
 package cache
 
+import (
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
 var pool = make(map[string]*RedisConnection)
+var poolLock sync.RWMutex
 
+// Connect creates the client of key if not exist, and pings it.
+// go-redis redials by itself, so the existing client is kept and shared by callers,
+// the network is not accessed with the lock held, so Get is not blocked during outages.
 func Connect(key string, config *RedisConfig) (err error) {
 
-	if conn, exist := pool[key]; exist {
+	conn, err := getOrCreate(key, config)
+	if err != nil {
+		return err
+	}
+
+	return conn.TryConnect()
+}
+
+func getOrCreate(key string, config *RedisConfig) (*RedisConnection, error) {
+
+	poolLock.Lock()
+	defer poolLock.Unlock()
+
+	conn, exist := pool[key]
+	if exist && conn.Client != nil {
+		return conn, nil
+	}
+
+	if exist && conn.RedisConfig != nil {
+		config = conn.RedisConfig
+	}
 
-		if conn.TryConnect() == nil {
-			return nil
-		}
+	if config == nil {
+		return nil, errorNotHaveConfig
 	}
 
-	conn := &RedisConnection{
-		Client:      nil,
+	conn = &RedisConnection{
+		Client:      redis.NewClient(config.GetClientOptions()),
 		RedisConfig: config,
 	}
 
 	pool[key] = conn
 
-	return conn.Connect()
+	return conn, nil
 }
 
+// Disconnect closes the client, and keeps only the config of key, so the next Connect creates a new client.
 func Disconnect(key string) (err error) {
 
+	poolLock.Lock()
 	conn, exist := pool[key]
+	if exist {
+		// the closed connection is not modified, because it may be used by the callers of Get
+		pool[key] = &RedisConnection{RedisConfig: conn.RedisConfig}
+	}
+	poolLock.Unlock()
+
 	if !exist {
 		return nil
 	}
@@ -31,8 +68,26 @@ func Disconnect(key string) (err error) {
 	return conn.Close()
 }
 
+// Ping checks the connection is alive, it returns error if the connection not exist or can not be reached.
+func Ping(key string) error {
+
+	conn, err := Get(key)
+	if err != nil {
+		return err
+	}
+
+	if conn.Client == nil {
+		return errorNotHaveConnection
+	}
+
+	return conn.TryConnect()
+}
+
 func Get(key string) (*RedisConnection, error) {
 
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
 	conn, exist := pool[key]
 	if !exist {
 		return nil, errorNotHaveConnection
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-redis/redis/v8"
@@ -192,3 +193,44 @@ func TestGet(t *testing.T) {
 		assert.Equal(t, test.wantError, actualError)
 	}
 }
+
+func TestConnect_KeepClient(t *testing.T) {
+
+	pool = make(map[string]*RedisConnection)
+	config := &RedisConfig{host: "127.0.0.1", port: 1}
+
+	// the ping fails, while the client is kept and redials by itself
+	assert.Error(t, Connect("unreachable", config))
+	conn, err := Get("unreachable")
+	assert.NoError(t, err)
+	assert.NotNil(t, conn.Client)
+
+	assert.Error(t, Connect("unreachable", config))
+	again, err := Get("unreachable")
+	assert.NoError(t, err)
+	assert.Same(t, conn, again)
+	assert.Same(t, conn.Client, again.Client)
+
+	assert.True(t, IsNotHaveConfigError(Connect("missing", nil)))
+}
+
+func TestDisconnect_Reconnect(t *testing.T) {
+
+	pool = make(map[string]*RedisConnection)
+	config := &RedisConfig{host: "127.0.0.1", port: 1}
+
+	assert.Error(t, Connect("unreachable", config))
+	conn, err := Get("unreachable")
+	assert.NoError(t, err)
+
+	assert.NoError(t, Disconnect("unreachable"))
+	assert.True(t, IsNotHaveConnectionError(Ping("unreachable")))
+
+	// the closed client is replaced, so the ping error is not "client is closed"
+	assert.True(t, IsPingFailedError(Connect("unreachable", nil)))
+	again, err := Get("unreachable")
+	assert.NoError(t, err)
+	assert.False(t, conn.Client == again.Client)
+	assert.NotEqual(t, "redis: client is closed", again.Ping(context.Background()).Err().Error())
+	assert.Equal(t, "redis: client is closed", conn.Ping(context.Background()).Err().Error())
+}
@@ -126,3 +126,12 @@ func (conn *RedisConnection) Connect() error {
 	conn.Client = redis.NewClient(conn.RedisConfig.GetClientOptions())
 	return conn.TryConnect()
 }
+
+func (conn *RedisConnection) Close() error {
+
+	if conn.Client == nil {
+		return nil
+	}
+
+	return conn.Client.Close()
+}
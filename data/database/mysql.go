@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -18,6 +19,8 @@ var (
 	}
 )
 
+var errorNotConnected = errors.New("database not connected")
+
 type MySQLOption func(config *MySQLConfig)
 
 func MySQLHost(host string) MySQLOption {
@@ -102,8 +105,9 @@ type Connection struct {
 
 func (conn *Connection) Connect() (err error) {
 
-	if conn.isConnected() {
-		return
+	// database/sql redials by itself, so the opened pool is only pinged
+	if db := conn.getDB(); db != nil {
+		return db.DB().Ping()
 	}
 
 	return conn.reconnect()
@@ -114,36 +118,49 @@ func (conn *Connection) reconnect() (err error) {
 	logger.WithField("connectionString", conn.config.getConnectionString()).
 		Info("mysql connect")
 
-	conn.DB, err = gorm.Open("mysql", conn.config.getConnectionString())
+	// the db returned with error is closed, so it's not assigned
+	db, err := gorm.Open("mysql", conn.config.getConnectionString())
 	if err != nil {
 		logger.Errorf("mysql connect: %v", err)
 		return
 	}
 
-	conn.DB.SingularTable(true)
+	db.SingularTable(true)
 
 	logger.Info("mysql connect succeed")
 
-	if settingError := conn.DB.Exec("SET time_zone='+08:00'").Error; settingError != nil {
+	if settingError := db.Exec("SET time_zone='+08:00'").Error; settingError != nil {
 		logger.WithError(settingError).Error("set time zone error")
 	} else {
 		logger.Info("set time zone for +08:00")
 	}
 
-	if settingError := conn.DB.Exec("SET NAMES utf8mb4").Error; settingError != nil {
+	if settingError := db.Exec("SET NAMES utf8mb4").Error; settingError != nil {
 		logger.WithError(settingError).Error("set names error")
 	} else {
 		logger.Info("set names utf8mb4")
 	}
 
 	logger.WithField("logMode", conn.config.logMode).Info("setting gorm log mode")
-	conn.DB.LogMode(conn.config.logMode)
+	db.LogMode(conn.config.logMode)
+
+	poolLock.Lock()
+	old := conn.DB
+	conn.DB = db
+	poolLock.Unlock()
+
+	// the connection may be opened concurrently, release the replaced pool
+	if old != nil {
+		_ = old.Close()
+	}
 
 	return
 }
 
 func (conn *Connection) isConnected() (returnValue bool) {
-	if conn.DB == nil {
+
+	connected := conn.getDB()
+	if connected == nil {
 		return false
 	}
 
@@ -158,7 +175,7 @@ func (conn *Connection) isConnected() (returnValue bool) {
 		return
 	}()
 
-	db := conn.DB.DB()
+	db := connected.DB()
 	if db == nil { // 根据 DB() 的实现，这个不可能出现
 		return false
 	}
@@ -170,12 +187,40 @@ func (conn *Connection) isConnected() (returnValue bool) {
 	return true
 }
 
+func (conn *Connection) getDB() *gorm.DB {
+
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
+	return conn.DB
+}
+
+func (conn *Connection) Ping() error {
+
+	db := conn.getDB()
+	if db == nil {
+		return errorNotConnected
+	}
+
+	sqlDB := db.DB()
+	if sqlDB == nil {
+		return errorNotConnected
+	}
+
+	return sqlDB.Ping()
+}
+
 func (conn *Connection) Begin() *gorm.DB {
 
-	return conn.DB.Begin()
+	return conn.getDB().Begin()
 }
 
 func (conn *Connection) Close() error {
 
-	return conn.DB.Close()
+	db := conn.getDB()
+	if db == nil {
+		return nil
+	}
+
+	return db.Close()
 }
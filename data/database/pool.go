@@ -3,34 +3,39 @@ package database
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/jinzhu/gorm"
 )
 
 var (
-	pool    = make(map[string]*Connection)
-	configs = make(map[string]*MySQLConfig)
+	pool     = make(map[string]*Connection)
+	poolLock sync.RWMutex
+	configs  = make(map[string]*MySQLConfig)
 )
 
+// Connect opens the connection of key if not exist, and pings it.
+// database/sql redials by itself, so the opened pool is kept, and the network is not accessed with the lock held.
 func Connect(key string, config *MySQLConfig) error {
 
-	if conn, ok := pool[key]; ok {
-		return conn.Connect()
-	}
+	poolLock.Lock()
+	conn, ok := pool[key]
+	if !ok {
+		conn = &Connection{
+			config: config,
+		}
 
-	conn := &Connection{
-		config: config,
+		// 将连接放入缓存
+		pool[key] = conn
 	}
-
-	// 将连接放入缓存
-	pool[key] = conn
+	poolLock.Unlock()
 
 	return conn.Connect()
 }
 
 func Disconnect(key string) error {
 
-	conn, ok := pool[key]
+	conn, ok := getConnection(key)
 	if !ok {
 		return errors.New(fmt.Sprintf("%s database connection not exist", key))
 	}
@@ -38,8 +43,31 @@ func Disconnect(key string) error {
 	return conn.Close()
 }
 
+// Ping checks the connection is alive, it returns error if the connection not exist or can not be reached.
+func Ping(key string) error {
+
+	conn, ok := getConnection(key)
+	if !ok {
+		return errors.New(fmt.Sprintf("%s database connection not exist", key))
+	}
+
+	return conn.Ping()
+}
+
+func getConnection(key string) (*Connection, bool) {
+
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
+	conn, ok := pool[key]
+	return conn, ok
+}
+
 func GetDB(key string) *gorm.DB {
 
+	poolLock.RLock()
+	defer poolLock.RUnlock()
+
 	if conn, ok := pool[key]; ok {
 		return conn.DB
 	}
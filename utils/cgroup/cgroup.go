@@ -0,0 +1,223 @@
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultRoot          = "/sys/fs/cgroup"
+	defaultProcSelfGroup = "/proc/self/cgroup"
+
+	// cgroup v1 use a very large number as unlimited memory, e.g. 9223372036854771712
+	unlimitedMemoryThreshold = uint64(1) << 62
+)
+
+var (
+	root          = defaultRoot
+	procSelfGroup = defaultProcSelfGroup
+)
+
+var errorInvalidFormat = errors.New("invalid cgroup file format")
+
+func IsInvalidFormatError(err error) bool {
+	return err == errorInvalidFormat
+}
+
+// CPUQuota returns the cpu cores limited by cgroup, e.g. 1.5. ok is false when not limited or not in cgroup.
+func CPUQuota() (quota float64, ok bool, err error) {
+
+	groups, err := readProcSelfGroup()
+	if err != nil {
+		return 0, false, err
+	}
+
+	// cgroup v2: "max 100000" or "150000 100000"
+	if groupPath, exist := groups[""]; exist {
+		content, found, err := readControllerFile("", groupPath, "cpu.max")
+		if err != nil || found {
+			return parseCPUMax(content, err)
+		}
+	}
+
+	groupPath, exist := groups["cpu"]
+	if !exist {
+		return 0, false, nil
+	}
+
+	quotaContent, found, err := readControllerFile("cpu", groupPath, "cpu.cfs_quota_us")
+	if err != nil || !found {
+		return 0, false, err
+	}
+
+	periodContent, found, err := readControllerFile("cpu", groupPath, "cpu.cfs_period_us")
+	if err != nil || !found {
+		return 0, false, err
+	}
+
+	quotaValue, err := strconv.ParseInt(quotaContent, 10, 64)
+	if err != nil {
+		return 0, false, errorInvalidFormat
+	}
+
+	periodValue, err := strconv.ParseInt(periodContent, 10, 64)
+	if err != nil {
+		return 0, false, errorInvalidFormat
+	}
+
+	if quotaValue <= 0 || periodValue <= 0 {
+		return 0, false, nil
+	}
+
+	return float64(quotaValue) / float64(periodValue), true, nil
+}
+
+func parseCPUMax(content string, err error) (float64, bool, error) {
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	fields := strings.Fields(content)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, false, errorInvalidFormat
+	}
+
+	if fields[0] == "max" {
+		return 0, false, nil
+	}
+
+	quotaValue, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, false, errorInvalidFormat
+	}
+
+	periodValue := int64(100000)
+	if len(fields) == 2 {
+		periodValue, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false, errorInvalidFormat
+		}
+	}
+
+	if quotaValue <= 0 || periodValue <= 0 {
+		return 0, false, nil
+	}
+
+	return float64(quotaValue) / float64(periodValue), true, nil
+}
+
+// MemoryLimit returns the memory bytes limited by cgroup. ok is false when not limited or not in cgroup.
+func MemoryLimit() (limit uint64, ok bool, err error) {
+
+	groups, err := readProcSelfGroup()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if groupPath, exist := groups[""]; exist {
+		content, found, err := readControllerFile("", groupPath, "memory.max")
+		if err != nil {
+			return 0, false, err
+		}
+		if found {
+			return parseMemoryLimit(content)
+		}
+	}
+
+	groupPath, exist := groups["memory"]
+	if !exist {
+		return 0, false, nil
+	}
+
+	content, found, err := readControllerFile("memory", groupPath, "memory.limit_in_bytes")
+	if err != nil || !found {
+		return 0, false, err
+	}
+
+	return parseMemoryLimit(content)
+}
+
+func parseMemoryLimit(content string) (uint64, bool, error) {
+
+	if content == "max" {
+		return 0, false, nil
+	}
+
+	limit, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, false, errorInvalidFormat
+	}
+
+	if limit == 0 || limit >= unlimitedMemoryThreshold {
+		return 0, false, nil
+	}
+
+	return limit, true, nil
+}
+
+// readProcSelfGroup returns the group path of every controller, key is "" for cgroup v2.
+// line format: hierarchy-ID:controller-list:cgroup-path, e.g. "4:cpu,cpuacct:/docker/xxx" or "0::/"
+func readProcSelfGroup() (map[string]string, error) {
+
+	file, err := os.Open(procSelfGroup)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if fields[1] == "" {
+			groups[""] = fields[2]
+			continue
+		}
+
+		for _, controller := range strings.Split(fields[1], ",") {
+			groups[controller] = fields[2]
+		}
+	}
+
+	return groups, scanner.Err()
+}
+
+// readControllerFile reads file in group path, falls back to the controller root when the group path is not mounted,
+// because the container only mounts its own group as root usually.
+func readControllerFile(controller, groupPath, fileName string) (content string, found bool, err error) {
+
+	candidates := []string{
+		path.Join(root, controller, groupPath, fileName),
+		path.Join(root, controller, fileName),
+	}
+
+	for _, candidate := range candidates {
+
+		data, err := ioutil.ReadFile(candidate)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", false, err
+		}
+
+		return strings.TrimSpace(string(data)), true, nil
+	}
+
+	return "", false, nil
+}
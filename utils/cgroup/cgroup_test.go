@@ -0,0 +1,191 @@
+package cgroup
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareFiles(t *testing.T, files map[string]string) string {
+
+	dir, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+
+		filePath := path.Join(dir, name)
+		if err = os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root = path.Join(dir, "sys")
+	procSelfGroup = path.Join(dir, "proc_self_cgroup")
+
+	return dir
+}
+
+func TestCPUQuota(t *testing.T) {
+
+	defer func() {
+		root = defaultRoot
+		procSelfGroup = defaultProcSelfGroup
+	}()
+
+	tests := []struct {
+		files     map[string]string
+		wantQuota float64
+		wantOk    bool
+		wantError error
+	}{
+		{
+			files:  map[string]string{},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup": "0::/\n",
+				"sys/cpu.max":      "max 100000\n",
+			},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup": "0::/kubepods/pod1\n",
+				"sys/cpu.max":      "150000 100000\n",
+			},
+			wantQuota: 1.5,
+			wantOk:    true,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":          "0::/kubepods/pod1\n",
+				"sys/kubepods/pod1/cpu.max": "200000 100000\n",
+				"sys/cpu.max":               "max 100000\n",
+			},
+			wantQuota: 2,
+			wantOk:    true,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":          "4:cpu,cpuacct:/docker/abc\n3:memory:/docker/abc\n",
+				"sys/cpu/cpu.cfs_quota_us":  "50000\n",
+				"sys/cpu/cpu.cfs_period_us": "100000\n",
+			},
+			wantQuota: 0.5,
+			wantOk:    true,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":          "4:cpu,cpuacct:/docker/abc\n",
+				"sys/cpu/cpu.cfs_quota_us":  "-1\n",
+				"sys/cpu/cpu.cfs_period_us": "100000\n",
+			},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup": "0::/\n",
+				"sys/cpu.max":      "a b c\n",
+			},
+			wantOk:    false,
+			wantError: errorInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+
+		dir := prepareFiles(t, test.files)
+
+		quota, ok, err := CPUQuota()
+		assert.Equal(t, test.wantQuota, quota, test.files)
+		assert.Equal(t, test.wantOk, ok, test.files)
+		assert.Equal(t, test.wantError, err, test.files)
+
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+
+	defer func() {
+		root = defaultRoot
+		procSelfGroup = defaultProcSelfGroup
+	}()
+
+	tests := []struct {
+		files     map[string]string
+		wantLimit uint64
+		wantOk    bool
+		wantError error
+	}{
+		{
+			files:  map[string]string{},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup": "0::/\n",
+				"sys/memory.max":   "max\n",
+			},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup": "0::/\n",
+				"sys/memory.max":   "536870912\n",
+			},
+			wantLimit: 536870912,
+			wantOk:    true,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":                 "3:memory:/docker/abc\n",
+				"sys/memory/memory.limit_in_bytes": "1073741824\n",
+			},
+			wantLimit: 1073741824,
+			wantOk:    true,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":                 "3:memory:/docker/abc\n",
+				"sys/memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			wantOk: false,
+		},
+		{
+			files: map[string]string{
+				"proc_self_cgroup":                 "3:memory:/docker/abc\n",
+				"sys/memory/memory.limit_in_bytes": "unknown\n",
+			},
+			wantOk:    false,
+			wantError: errorInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+
+		dir := prepareFiles(t, test.files)
+
+		limit, ok, err := MemoryLimit()
+		assert.Equal(t, test.wantLimit, limit, test.files)
+		assert.Equal(t, test.wantOk, ok, test.files)
+		assert.Equal(t, test.wantError, err, test.files)
+
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestIsInvalidFormatError(t *testing.T) {
+
+	assert.True(t, IsInvalidFormatError(errorInvalidFormat))
+	assert.False(t, IsInvalidFormatError(os.ErrNotExist))
+}
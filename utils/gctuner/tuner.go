@@ -0,0 +1,121 @@
+package gctuner
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+const (
+	DefaultMinGCPercent = 25
+	DefaultMaxGCPercent = 500
+)
+
+// Tuner adjusts GOGC after every GC cycle, makes the next heap goal close to heap target,
+// so the process uses memory as much as possible and not exceed the memory limit.
+type Tuner struct {
+	heapTarget   uint64
+	minGCPercent int
+	maxGCPercent int
+	gcPercent    int64
+	stopped      int32
+}
+
+type Option func(tuner *Tuner)
+
+func MinGCPercent(percent int) Option {
+
+	return func(tuner *Tuner) {
+		tuner.minGCPercent = percent
+	}
+}
+
+func MaxGCPercent(percent int) Option {
+
+	return func(tuner *Tuner) {
+		tuner.maxGCPercent = percent
+	}
+}
+
+func NewTuner(heapTarget uint64, options ...Option) *Tuner {
+
+	tuner := &Tuner{
+		heapTarget:   heapTarget,
+		minGCPercent: DefaultMinGCPercent,
+		maxGCPercent: DefaultMaxGCPercent,
+	}
+
+	for _, option := range options {
+		option(tuner)
+	}
+
+	return tuner
+}
+
+// finalizerRef is collected by every GC cycle, its finalizer is the hook to tune after GC.
+type finalizerRef struct {
+	tuner *Tuner
+}
+
+func (tuner *Tuner) Start() {
+
+	atomic.StoreInt32(&tuner.stopped, 0)
+	runtime.SetFinalizer(&finalizerRef{tuner: tuner}, onGC)
+}
+
+func (tuner *Tuner) Stop() {
+
+	atomic.StoreInt32(&tuner.stopped, 1)
+}
+
+// GetGCPercent returns the GOGC value set by the last tuning.
+func (tuner *Tuner) GetGCPercent() int {
+
+	return int(atomic.LoadInt64(&tuner.gcPercent))
+}
+
+func onGC(ref *finalizerRef) {
+
+	if atomic.LoadInt32(&ref.tuner.stopped) == 1 {
+		return
+	}
+
+	ref.tuner.tune()
+	runtime.SetFinalizer(ref, onGC)
+}
+
+func (tuner *Tuner) tune() {
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	percent := calculateGCPercent(tuner.heapTarget, stats.HeapAlloc, tuner.minGCPercent, tuner.maxGCPercent)
+	if int64(percent) == atomic.LoadInt64(&tuner.gcPercent) {
+		return
+	}
+
+	atomic.StoreInt64(&tuner.gcPercent, int64(percent))
+	debug.SetGCPercent(percent)
+}
+
+// calculateGCPercent returns GOGC makes next heap goal (liveHeap * (1 + GOGC/100)) equals heap target.
+func calculateGCPercent(heapTarget, liveHeap uint64, minimum, maximum int) int {
+
+	if liveHeap == 0 {
+		return maximum
+	}
+
+	if liveHeap >= heapTarget {
+		return minimum
+	}
+
+	percent := int((heapTarget - liveHeap) * 100 / liveHeap)
+	if percent < minimum {
+		return minimum
+	}
+	if percent > maximum {
+		return maximum
+	}
+
+	return percent
+}
@@ -0,0 +1,54 @@
+package gctuner
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateGCPercent(t *testing.T) {
+
+	tests := []struct {
+		heapTarget uint64
+		liveHeap   uint64
+		want       int
+	}{
+		{heapTarget: 1000, liveHeap: 0, want: DefaultMaxGCPercent},
+		{heapTarget: 1000, liveHeap: 1000, want: DefaultMinGCPercent},
+		{heapTarget: 1000, liveHeap: 2000, want: DefaultMinGCPercent},
+		{heapTarget: 1000, liveHeap: 500, want: 100},
+		{heapTarget: 1000, liveHeap: 900, want: DefaultMinGCPercent},
+		{heapTarget: 1000, liveHeap: 100, want: DefaultMaxGCPercent},
+		{heapTarget: 1000, liveHeap: 400, want: 150},
+	}
+
+	for _, test := range tests {
+
+		assert.Equal(t, test.want, calculateGCPercent(test.heapTarget, test.liveHeap, DefaultMinGCPercent, DefaultMaxGCPercent), test)
+	}
+}
+
+func TestNewTuner(t *testing.T) {
+
+	tuner := NewTuner(1024, MinGCPercent(50), MaxGCPercent(200))
+	assert.Equal(t, &Tuner{heapTarget: 1024, minGCPercent: 50, maxGCPercent: 200}, tuner)
+}
+
+func TestTuner_Start(t *testing.T) {
+
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner := NewTuner(1<<40, MaxGCPercent(300))
+	tuner.Start()
+	defer tuner.Stop()
+
+	for i := 0; i < 3 && tuner.GetGCPercent() == 0; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, 300, tuner.GetGCPercent())
+}
@@ -7,13 +7,15 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/errors"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/codec"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/request/requestid"
 	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/gin/response"
+	"dev-gitlab.wanxingrowth.com/wanxin-go-micro/base/api/i18n"
 )
 
 func Params(c *gin.Context, v Validator) error {
 
-	if err := c.ShouldBindJSON(v); err != nil {
+	if err := codec.Bind(c, v); err != nil {
 		return response.ResponseData{
 			Status: http.StatusOK,
 			Code:   errors.CodeRequestParamError,
@@ -23,6 +25,7 @@ func Params(c *gin.Context, v Validator) error {
 	reqId := requestid.GetRequestId(c)
 	logrus.Debugf("reqId: %s, v = %+v\n", reqId, v)
 	if err := v.Validate(); err != nil {
+		err = i18n.LocalizeError(i18n.GetLanguage(c), err)
 		return response.ResponseData{
 			Status: http.StatusOK,
 			Code:   errors.CodeRequestParamError,